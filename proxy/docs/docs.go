@@ -0,0 +1,260 @@
+// Package docs — спецификация Swagger, сгенерированная swag.
+package docs
+
+import "github.com/swaggo/swag"
+
+const docTemplate = `{
+    "schemes": {{ marshal .Schemes }},
+    "swagger": "2.0",
+    "info": {
+        "description": "{{escape .Description}}",
+        "title": "{{.Title}}",
+        "contact": {},
+        "version": "{{.Version}}"
+    },
+    "host": "{{.Host}}",
+    "basePath": "{{.BasePath}}",
+    "paths": {
+        "/api/address/geocode": {
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "consumes": ["application/json"],
+                "produces": ["application/json"],
+                "tags": ["address"],
+                "summary": "Геокодирование по координатам",
+                "parameters": [
+                    {
+                        "description": "Координаты",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {"$ref": "#/definitions/controller.GeocodeRequest"}
+                    }
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/controller.SearchResponse"}},
+                    "400": {"description": "Bad Request", "schema": {"$ref": "#/definitions/responder.ErrorResponse"}}
+                }
+            }
+        },
+        "/api/address/search": {
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "consumes": ["application/json"],
+                "produces": ["application/json"],
+                "tags": ["address"],
+                "summary": "Поиск адреса",
+                "parameters": [
+                    {
+                        "description": "Строка поиска",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {"$ref": "#/definitions/controller.SearchRequest"}
+                    }
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/controller.SearchResponse"}},
+                    "400": {"description": "Bad Request", "schema": {"$ref": "#/definitions/responder.ErrorResponse"}}
+                }
+            }
+        },
+        "/api/login": {
+            "post": {
+                "consumes": ["application/json"],
+                "produces": ["application/json"],
+                "tags": ["auth"],
+                "summary": "Вход пользователя",
+                "parameters": [
+                    {
+                        "description": "Учётные данные",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {"$ref": "#/definitions/controller.LoginRequest"}
+                    }
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/controller.TokenResponse"}},
+                    "400": {"description": "Bad Request", "schema": {"$ref": "#/definitions/responder.ErrorResponse"}},
+                    "403": {"description": "Forbidden", "schema": {"$ref": "#/definitions/responder.ErrorResponse"}}
+                }
+            }
+        },
+        "/api/register": {
+            "post": {
+                "consumes": ["application/json"],
+                "produces": ["application/json"],
+                "tags": ["auth"],
+                "summary": "Регистрация пользователя",
+                "parameters": [
+                    {
+                        "description": "Учётные данные",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {"$ref": "#/definitions/controller.RegisterRequest"}
+                    }
+                ],
+                "responses": {
+                    "201": {"description": "Created", "schema": {"$ref": "#/definitions/entity.User"}},
+                    "400": {"description": "Bad Request", "schema": {"$ref": "#/definitions/responder.ErrorResponse"}},
+                    "409": {"description": "Conflict", "schema": {"$ref": "#/definitions/responder.ErrorResponse"}}
+                }
+            }
+        },
+        "/api/users": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "produces": ["application/json"],
+                "tags": ["users"],
+                "summary": "Список пользователей",
+                "parameters": [
+                    {"type": "integer", "description": "Размер страницы", "name": "limit", "in": "query"},
+                    {"type": "integer", "description": "Смещение", "name": "offset", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/entity.User"}}}
+                }
+            }
+        },
+        "/api/users/{id}": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "produces": ["application/json"],
+                "tags": ["users"],
+                "summary": "Пользователь по ID",
+                "parameters": [
+                    {"type": "integer", "description": "ID пользователя", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/entity.User"}},
+                    "404": {"description": "Not Found", "schema": {"$ref": "#/definitions/responder.ErrorResponse"}}
+                }
+            },
+            "put": {
+                "security": [{"BearerAuth": []}],
+                "consumes": ["application/json"],
+                "produces": ["application/json"],
+                "tags": ["users"],
+                "summary": "Обновление пользователя",
+                "parameters": [
+                    {"type": "integer", "description": "ID пользователя", "name": "id", "in": "path", "required": true},
+                    {
+                        "description": "Новые данные",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {"$ref": "#/definitions/entity.User"}
+                    }
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/entity.User"}},
+                    "404": {"description": "Not Found", "schema": {"$ref": "#/definitions/responder.ErrorResponse"}}
+                }
+            },
+            "delete": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["users"],
+                "summary": "Удаление пользователя",
+                "parameters": [
+                    {"type": "integer", "description": "ID пользователя", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "204": {"description": "No Content"},
+                    "404": {"description": "Not Found", "schema": {"$ref": "#/definitions/responder.ErrorResponse"}}
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controller.GeocodeRequest": {
+            "type": "object",
+            "properties": {
+                "lat": {"type": "string"},
+                "lon": {"type": "string"}
+            }
+        },
+        "controller.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "email": {"type": "string"},
+                "password": {"type": "string"}
+            }
+        },
+        "controller.RegisterRequest": {
+            "type": "object",
+            "properties": {
+                "email": {"type": "string"},
+                "password": {"type": "string"}
+            }
+        },
+        "controller.SearchRequest": {
+            "type": "object",
+            "properties": {
+                "query": {"type": "string"}
+            }
+        },
+        "controller.SearchResponse": {
+            "type": "object",
+            "properties": {
+                "addresses": {"type": "array", "items": {"$ref": "#/definitions/entity.Address"}}
+            }
+        },
+        "controller.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "token": {"type": "string"}
+            }
+        },
+        "entity.Address": {
+            "type": "object",
+            "properties": {
+                "value": {"type": "string"},
+                "city": {"type": "string"},
+                "street": {"type": "string"},
+                "house": {"type": "string"},
+                "lat": {"type": "string"},
+                "lon": {"type": "string"}
+            }
+        },
+        "entity.User": {
+            "type": "object",
+            "properties": {
+                "id": {"type": "integer"},
+                "email": {"type": "string"},
+                "created_at": {"type": "string"},
+                "updated_at": {"type": "string"}
+            }
+        },
+        "responder.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {"type": "string"}
+            }
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
+    }
+}`
+
+// SwaggerInfo holds exported Swagger Info so clients can modify it
+var SwaggerInfo = &swag.Spec{
+	Version:          "1.0",
+	Host:             "localhost:8080",
+	BasePath:         "/",
+	Schemes:          []string{},
+	Title:            "Geoservis API",
+	Description:      "Геолокационный сервис: поиск и геокодирование адресов через Dadata.",
+	InfoInstanceName: "swagger",
+	SwaggerTemplate:  docTemplate,
+}
+
+func init() {
+	swag.Register(SwaggerInfo.InstanceName(), SwaggerInfo)
+}
@@ -0,0 +1,220 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache — интерфейс кэша для ответов внешних сервисов.
+type Cache interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{}, ttl time.Duration)
+	Delete(key string)
+	// Touch продлевает время жизни записи; сообщает, существовала ли она.
+	Touch(key string, ttl time.Duration) bool
+	// GetOrSet возвращает значение по ключу, а при промахе вычисляет его
+	// через fn и сохраняет с заданным TTL.
+	GetOrSet(key string, ttl time.Duration, fn func() (interface{}, error)) (interface{}, error)
+	// Clear полностью очищает кэш.
+	Clear()
+	// Close останавливает фоновую очистку; кэш после этого использовать нельзя.
+	Close()
+}
+
+type item struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// clock абстрагирует источник времени кэша: тесты истечения TTL продвигают
+// поддельные часы вместо настоящего ожидания.
+type clock interface {
+	Now() time.Time
+}
+
+// realClock — системное время.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// defaultCleanupBatch — размер пачки фоновой очистки по умолчанию.
+const defaultCleanupBatch = 1024
+
+// InMemoryCache — потокобезопасный кэш в памяти с TTL.
+type InMemoryCache struct {
+	mu    sync.RWMutex
+	items map[string]item
+	clk   clock
+	// cleanupBatch ограничивает число записей, проверяемых под одной
+	// блокировкой записи при фоновой очистке.
+	cleanupBatch int
+	stop         chan struct{}
+	closeOnce    sync.Once
+}
+
+// NewInMemoryCache создаёт кэш и запускает фоновую очистку просроченных записей.
+func NewInMemoryCache(cleanupInterval time.Duration) *InMemoryCache {
+	return newInMemoryCacheWithClock(cleanupInterval, realClock{})
+}
+
+// newInMemoryCacheWithClock создаёт кэш с заданным источником времени —
+// для тестов истечения TTL.
+func newInMemoryCacheWithClock(cleanupInterval time.Duration, clk clock) *InMemoryCache {
+	c := &InMemoryCache{
+		items:        make(map[string]item),
+		clk:          clk,
+		cleanupBatch: defaultCleanupBatch,
+		stop:         make(chan struct{}),
+	}
+	go c.startCleanup(cleanupInterval)
+	return c
+}
+
+// WithCleanupBatch задаёт размер пачки фоновой очистки (переменная
+// CACHE_CLEANUP_BATCH): чем меньше пачка, тем короче блокировка записи на
+// большом кэше. n <= 0 оставляет размер по умолчанию.
+func (c *InMemoryCache) WithCleanupBatch(n int) *InMemoryCache {
+	if n <= 0 {
+		return c
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cleanupBatch = n
+	return c
+}
+
+// Get возвращает значение по ключу, если оно есть и не просрочено.
+func (c *InMemoryCache) Get(key string) (interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	it, ok := c.items[key]
+	if !ok || c.clk.Now().After(it.expiresAt) {
+		return nil, false
+	}
+	return it.value, true
+}
+
+// GetWithExpiry возвращает значение и момент истечения записи. Просроченная
+// или отсутствующая запись даёт ok == false.
+func (c *InMemoryCache) GetWithExpiry(key string) (interface{}, time.Time, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	it, ok := c.items[key]
+	if !ok || c.clk.Now().After(it.expiresAt) {
+		return nil, time.Time{}, false
+	}
+	return it.value, it.expiresAt, true
+}
+
+// Set сохраняет значение с заданным временем жизни.
+func (c *InMemoryCache) Set(key string, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items[key] = item{
+		value:     value,
+		expiresAt: c.clk.Now().Add(ttl),
+	}
+}
+
+// Delete удаляет запись по ключу.
+func (c *InMemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.items, key)
+}
+
+// GetOrSet возвращает значение по ключу, а при промахе вычисляет его через
+// fn и сохраняет. fn выполняется вне блокировки, поэтому параллельные
+// промахи могут вычислить значение дважды — побеждает последняя запись.
+// При ошибке fn в кэш ничего не сохраняется.
+func (c *InMemoryCache) GetOrSet(key string, ttl time.Duration, fn func() (interface{}, error)) (interface{}, error) {
+	if v, ok := c.Get(key); ok {
+		return v, nil
+	}
+
+	v, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	c.Set(key, v, ttl)
+	return v, nil
+}
+
+// Touch продлевает время жизни непросроченной записи. Возвращает false,
+// если записи нет или она уже истекла.
+func (c *InMemoryCache) Touch(key string, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	it, ok := c.items[key]
+	if !ok || c.clk.Now().After(it.expiresAt) {
+		return false
+	}
+	it.expiresAt = c.clk.Now().Add(ttl)
+	c.items[key] = it
+	return true
+}
+
+// Clear полностью очищает кэш.
+func (c *InMemoryCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[string]item)
+}
+
+// Close останавливает горутину фоновой очистки. Повторные вызовы безопасны.
+func (c *InMemoryCache) Close() {
+	c.closeOnce.Do(func() {
+		close(c.stop)
+	})
+}
+
+func (c *InMemoryCache) startCleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.cleanupExpired()
+		}
+	}
+}
+
+// cleanupExpired удаляет просроченные записи пачками, отпуская блокировку
+// записи между ними: на большом кэше чтения не ждут обхода всей карты.
+// Запись, продлённая между снимком ключей и её пачкой, перепроверяется и
+// остаётся на месте.
+func (c *InMemoryCache) cleanupExpired() {
+	c.mu.RLock()
+	batch := c.cleanupBatch
+	keys := make([]string, 0, len(c.items))
+	for key := range c.items {
+		keys = append(keys, key)
+	}
+	c.mu.RUnlock()
+
+	for start := 0; start < len(keys); start += batch {
+		end := start + batch
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		c.mu.Lock()
+		now := c.clk.Now()
+		for _, key := range keys[start:end] {
+			if it, ok := c.items[key]; ok && now.After(it.expiresAt) {
+				delete(c.items, key)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
@@ -0,0 +1,286 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInMemoryCache_SetGet(t *testing.T) {
+	c := NewInMemoryCache(time.Minute)
+
+	c.Set("key", "value", time.Minute)
+
+	got, ok := c.Get("key")
+	if !ok {
+		t.Fatal("expected key to be present")
+	}
+	if got != "value" {
+		t.Errorf("got %v, want value", got)
+	}
+
+	if _, ok := c.Get("missing"); ok {
+		t.Error("expected missing key to be absent")
+	}
+}
+
+func TestInMemoryCache_Delete(t *testing.T) {
+	c := NewInMemoryCache(time.Minute)
+
+	c.Set("key", "value", time.Minute)
+	c.Delete("key")
+
+	if _, ok := c.Get("key"); ok {
+		t.Error("expected key to be deleted")
+	}
+}
+
+func TestInMemoryCache_GetOrSet(t *testing.T) {
+	c := NewInMemoryCache(time.Minute)
+	defer c.Close()
+
+	t.Run("hit does not call fn", func(t *testing.T) {
+		c.Set("hit", "cached", time.Minute)
+		v, err := c.GetOrSet("hit", time.Minute, func() (interface{}, error) {
+			t.Error("fn must not be called on a cache hit")
+			return nil, nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if v != "cached" {
+			t.Errorf("got %v, want cached", v)
+		}
+	})
+
+	t.Run("miss computes and stores", func(t *testing.T) {
+		calls := 0
+		v, err := c.GetOrSet("miss", time.Minute, func() (interface{}, error) {
+			calls++
+			return "computed", nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if v != "computed" || calls != 1 {
+			t.Errorf("got %v (%d calls), want computed (1 call)", v, calls)
+		}
+		if stored, ok := c.Get("miss"); !ok || stored != "computed" {
+			t.Errorf("computed value must be stored, got %v (%v)", stored, ok)
+		}
+	})
+
+	t.Run("fn error stores nothing", func(t *testing.T) {
+		wantErr := errors.New("boom")
+		if _, err := c.GetOrSet("err", time.Minute, func() (interface{}, error) {
+			return nil, wantErr
+		}); !errors.Is(err, wantErr) {
+			t.Fatalf("got %v, want %v", err, wantErr)
+		}
+		if _, ok := c.Get("err"); ok {
+			t.Error("nothing must be stored when fn fails")
+		}
+	})
+}
+
+func TestInMemoryCache_TouchExtendsTTL(t *testing.T) {
+	c := NewInMemoryCache(time.Minute)
+	defer c.Close()
+
+	c.Set("key", "value", 10*time.Millisecond)
+	if !c.Touch("key", time.Minute) {
+		t.Fatal("Touch must report true for an existing key")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := c.Get("key"); !ok {
+		t.Error("touched entry must survive its original TTL")
+	}
+}
+
+func TestInMemoryCache_TouchMissingKey(t *testing.T) {
+	c := NewInMemoryCache(time.Minute)
+	defer c.Close()
+
+	if c.Touch("missing", time.Minute) {
+		t.Error("Touch must report false for a missing key")
+	}
+
+	c.Set("expired", "value", -time.Second)
+	if c.Touch("expired", time.Minute) {
+		t.Error("Touch must report false for an expired key")
+	}
+}
+
+func TestInMemoryCache_CloseStopsCleanup(t *testing.T) {
+	before := runtime.NumGoroutine()
+	c := NewInMemoryCache(time.Millisecond)
+	c.Close()
+	// Повторный вызов не должен паниковать.
+	c.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if runtime.NumGoroutine() <= before {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Errorf("cleanup goroutine still running: %d goroutines, was %d",
+		runtime.NumGoroutine(), before)
+}
+
+func TestInMemoryCache_Clear(t *testing.T) {
+	c := NewInMemoryCache(time.Minute)
+
+	c.Set("key1", "value", time.Minute)
+	c.Set("key2", "value", time.Minute)
+	c.Clear()
+
+	if _, ok := c.Get("key1"); ok {
+		t.Error("expected key1 to be flushed")
+	}
+	if _, ok := c.Get("key2"); ok {
+		t.Error("expected key2 to be flushed")
+	}
+}
+
+func TestInMemoryCache_Expiration(t *testing.T) {
+	clk := newFakeClock()
+	c := newInMemoryCacheWithClock(time.Minute, clk)
+	defer c.Close()
+
+	c.Set("key", "value", time.Minute)
+
+	if _, ok := c.Get("key"); !ok {
+		t.Fatal("fresh entry must be readable")
+	}
+
+	clk.Advance(time.Minute + time.Second)
+	if _, ok := c.Get("key"); ok {
+		t.Error("expected key to expire")
+	}
+}
+
+func TestInMemoryCache_TouchExtendsLifetime(t *testing.T) {
+	clk := newFakeClock()
+	c := newInMemoryCacheWithClock(time.Minute, clk)
+	defer c.Close()
+
+	c.Set("key", "value", time.Minute)
+
+	clk.Advance(30 * time.Second)
+	if !c.Touch("key", time.Minute) {
+		t.Fatal("Touch must succeed for a live entry")
+	}
+
+	// Исходный TTL уже истёк, но запись продлена.
+	clk.Advance(45 * time.Second)
+	if _, ok := c.Get("key"); !ok {
+		t.Error("touched entry must survive its original TTL")
+	}
+
+	clk.Advance(time.Minute)
+	if _, ok := c.Get("key"); ok {
+		t.Error("touched entry must still expire eventually")
+	}
+}
+
+// fakeClock — управляемые часы для тестов истечения TTL. Счётчик calls
+// служит инструментировкой фоновой очистки: она читает часы ровно один раз
+// на каждую блокировку записи.
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	calls int
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1700000000, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	return c.now
+}
+
+func (c *fakeClock) Calls() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestInMemoryCache_CleanupRunsInBatches(t *testing.T) {
+	const (
+		entries = 1000
+		batch   = 100
+	)
+	clk := newFakeClock()
+	c := newInMemoryCacheWithClock(time.Hour, clk).WithCleanupBatch(batch)
+	defer c.Close()
+
+	for i := 0; i < entries; i++ {
+		c.Set(fmt.Sprintf("key%d", i), i, time.Minute)
+	}
+	// keeper живёт дольше и очистку пережить обязан.
+	c.Set("keeper", "value", time.Hour)
+	clk.Advance(2 * time.Minute)
+
+	before := clk.Calls()
+	c.cleanupExpired()
+	holds := clk.Calls() - before
+
+	c.mu.RLock()
+	left := len(c.items)
+	c.mu.RUnlock()
+	if left != 1 {
+		t.Errorf("got %d entries after cleanup, want only the live one", left)
+	}
+	if _, ok := c.Get("keeper"); !ok {
+		t.Error("live entry must survive cleanup")
+	}
+
+	// Очистка читает часы один раз на блокировку: 1001 запись при пачке
+	// в 100 должна дать 11 коротких блокировок, а не одну длинную.
+	if want := entries/batch + 1; holds != want {
+		t.Errorf("got %d lock holds, want %d batches of at most %d entries", holds, want, batch)
+	}
+}
+
+func TestInMemoryCache_GetWithExpiry(t *testing.T) {
+	clk := newFakeClock()
+	c := newInMemoryCacheWithClock(time.Minute, clk)
+	defer c.Close()
+
+	c.Set("key", "value", time.Minute)
+
+	v, expiresAt, ok := c.GetWithExpiry("key")
+	if !ok {
+		t.Fatal("live entry must be found")
+	}
+	if v != "value" {
+		t.Errorf("got value %v, want value", v)
+	}
+	if remaining := expiresAt.Sub(clk.Now()); remaining != time.Minute {
+		t.Errorf("got remaining TTL %s, want 1m", remaining)
+	}
+
+	clk.Advance(2 * time.Minute)
+	if _, _, ok := c.GetWithExpiry("key"); ok {
+		t.Error("expired entry must not be found")
+	}
+	if _, _, ok := c.GetWithExpiry("missing"); ok {
+		t.Error("missing entry must not be found")
+	}
+}
@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PostgresCache — реализация Cache поверх таблицы cache_entries. Позволяет
+// делить кэш между несколькими экземплярами сервиса без Redis.
+// Get возвращает значение как json.RawMessage.
+type PostgresCache struct {
+	db        *sqlx.DB
+	logger    *slog.Logger
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewPostgresCache создаёт кэш поверх базы и запускает фоновую очистку
+// просроченных записей.
+func NewPostgresCache(db *sqlx.DB, cleanupInterval time.Duration, logger *slog.Logger) *PostgresCache {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	c := &PostgresCache{db: db, logger: logger, stop: make(chan struct{})}
+	go c.startCleanup(cleanupInterval)
+	return c
+}
+
+// Get возвращает значение по ключу, если оно есть и не просрочено.
+func (c *PostgresCache) Get(key string) (interface{}, bool) {
+	const query = `SELECT value FROM cache_entries WHERE key = $1 AND expires_at > now()`
+
+	var raw []byte
+	if err := c.db.QueryRow(query, key).Scan(&raw); err != nil {
+		return nil, false
+	}
+	return json.RawMessage(raw), true
+}
+
+// Set сохраняет значение с заданным временем жизни. Значение должно
+// сериализоваться в JSON; иначе запись пропускается с записью в журнал.
+func (c *PostgresCache) Set(key string, value interface{}, ttl time.Duration) {
+	const query = `
+		INSERT INTO cache_entries (key, value, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at`
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		c.logger.Error("cache set: marshal value", "key", key, "error", err)
+		return
+	}
+	if _, err := c.db.Exec(query, key, raw, time.Now().Add(ttl)); err != nil {
+		c.logger.Error("cache set", "key", key, "error", err)
+	}
+}
+
+// Delete удаляет запись по ключу.
+func (c *PostgresCache) Delete(key string) {
+	if _, err := c.db.Exec(`DELETE FROM cache_entries WHERE key = $1`, key); err != nil {
+		c.logger.Error("cache delete", "key", key, "error", err)
+	}
+}
+
+// GetOrSet возвращает значение по ключу, а при промахе вычисляет его через
+// fn и сохраняет. Блокировок между экземплярами нет: параллельные промахи
+// могут вычислить значение дважды — побеждает последняя запись.
+func (c *PostgresCache) GetOrSet(key string, ttl time.Duration, fn func() (interface{}, error)) (interface{}, error) {
+	if v, ok := c.Get(key); ok {
+		return v, nil
+	}
+
+	v, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	c.Set(key, v, ttl)
+	return v, nil
+}
+
+// Touch продлевает время жизни непросроченной записи. Возвращает false,
+// если записи нет или она уже истекла.
+func (c *PostgresCache) Touch(key string, ttl time.Duration) bool {
+	const query = `UPDATE cache_entries SET expires_at = $2 WHERE key = $1 AND expires_at > now()`
+
+	res, err := c.db.Exec(query, key, time.Now().Add(ttl))
+	if err != nil {
+		c.logger.Error("cache touch", "key", key, "error", err)
+		return false
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false
+	}
+	return affected > 0
+}
+
+// Clear полностью очищает кэш.
+func (c *PostgresCache) Clear() {
+	if _, err := c.db.Exec(`DELETE FROM cache_entries`); err != nil {
+		c.logger.Error("cache clear", "error", err)
+	}
+}
+
+// Close останавливает горутину фоновой очистки. Повторные вызовы безопасны.
+func (c *PostgresCache) Close() {
+	c.closeOnce.Do(func() {
+		close(c.stop)
+	})
+}
+
+func (c *PostgresCache) startCleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			if _, err := c.db.Exec(`DELETE FROM cache_entries WHERE expires_at <= now()`); err != nil {
+				c.logger.Error("cache cleanup", "error", err)
+			}
+		}
+	}
+}
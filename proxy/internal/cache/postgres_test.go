@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func newPostgresCache(t *testing.T) (*PostgresCache, sqlmock.Sqlmock) {
+	t.Helper()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { mockDB.Close() })
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	// Очистку здесь не запускаем: тестируем только Get/Set/Delete.
+	return &PostgresCache{db: sqlx.NewDb(mockDB, "sqlmock"), logger: logger}, mock
+}
+
+func TestPostgresCache_SetUpserts(t *testing.T) {
+	c, mock := newPostgresCache(t)
+
+	mock.ExpectExec("INSERT INTO cache_entries").
+		WithArgs("key1", []byte(`{"city":"Москва"}`), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	c.Set("key1", map[string]string{"city": "Москва"}, time.Minute)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresCache_GetHitReturnsRawJSON(t *testing.T) {
+	c, mock := newPostgresCache(t)
+
+	rows := sqlmock.NewRows([]string{"value"}).AddRow([]byte(`{"city":"Москва"}`))
+	mock.ExpectQuery("SELECT value FROM cache_entries").
+		WithArgs("key1").
+		WillReturnRows(rows)
+
+	v, ok := c.Get("key1")
+	if !ok {
+		t.Fatal("expected cache hit")
+	}
+	raw, ok := v.(json.RawMessage)
+	if !ok {
+		t.Fatalf("got %T, want json.RawMessage", v)
+	}
+
+	var decoded map[string]string
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("unmarshal cached value: %v", err)
+	}
+	if decoded["city"] != "Москва" {
+		t.Errorf("got %q, want Москва", decoded["city"])
+	}
+}
+
+func TestPostgresCache_GetMissOnExpired(t *testing.T) {
+	c, mock := newPostgresCache(t)
+
+	// Просроченные строки отфильтровываются условием expires_at > now(),
+	// поэтому база не возвращает строк.
+	mock.ExpectQuery("SELECT value FROM cache_entries").
+		WithArgs("stale").
+		WillReturnRows(sqlmock.NewRows([]string{"value"}))
+
+	if _, ok := c.Get("stale"); ok {
+		t.Error("expected miss for expired entry")
+	}
+}
+
+func TestPostgresCache_Clear(t *testing.T) {
+	c, mock := newPostgresCache(t)
+
+	mock.ExpectExec("DELETE FROM cache_entries").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	c.Clear()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresCache_Delete(t *testing.T) {
+	c, mock := newPostgresCache(t)
+
+	mock.ExpectExec("DELETE FROM cache_entries").
+		WithArgs("key1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	c.Delete("key1")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,420 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/version"
+)
+
+// Config — конфигурация сервиса, собирается из переменных окружения.
+type Config struct {
+	ListenAddr string
+
+	// PublicBaseURL — внешний адрес сервиса (для ссылок в Swagger UI).
+	// Пустое значение — адрес берётся из Host входящего запроса.
+	PublicBaseURL string
+
+	DBHost     string
+	DBPort     string
+	DBUser     string
+	DBPassword string
+	DBName     string
+
+	// DBQueryTimeout — максимальная длительность одного запроса к базе.
+	DBQueryTimeout time.Duration
+
+	// DBSlowQueryThreshold — порог, после которого запрос считается
+	// медленным и попадает в журнал с предупреждением.
+	DBSlowQueryThreshold time.Duration
+
+	JWTSecret string
+
+	// JWTOldSecrets — прежние секреты подписи JWT. Токены, подписанные ими,
+	// ещё принимаются в переходный период ротации ключа; новые токены
+	// подписываются только JWTSecret.
+	JWTOldSecrets []string
+
+	// JWTIssuer и JWTAudience — ожидаемые клеймы iss/aud токена.
+	// Пустое значение отключает соответствующую проверку.
+	JWTIssuer   string
+	JWTAudience string
+	TokenTTL    time.Duration
+
+	// RequestTimeout — предельное суммарное время обработки одного HTTP-
+	// запроса; по истечении контекст запроса отменяется. 0 — без лимита.
+	RequestTimeout time.Duration
+
+	// PrettyJSON включает форматирование JSON-ответов с отступами —
+	// для отладочных стендов; в проде вывод компактный.
+	PrettyJSON bool
+
+	DadataAPIKey    string
+	DadataSecretKey string
+
+	// DadataBaseURL — базовый адрес API Dadata для on-premise установок.
+	// Пустое значение — облачный API.
+	DadataBaseURL string
+
+	// GeoProvider — провайдер геокодирования: "dadata" (по умолчанию)
+	// или "nominatim".
+	GeoProvider string
+
+	// NominatimBaseURL — базовый адрес Nominatim. Пустое значение —
+	// публичный инстанс OSM.
+	NominatimBaseURL string
+
+	// GeoUserAgent — User-Agent исходящих запросов к Dadata.
+	GeoUserAgent string
+
+	// GeoDebug включает журналирование точных запросов и сырых ответов
+	// геокодирования. Только для отладки: в журналы попадают адреса.
+	GeoDebug bool
+
+	// GeoMaxResults ограничивает число адресов в ответе поиска независимо
+	// от того, сколько подсказок прислал провайдер. 0 — без ограничения.
+	GeoMaxResults int
+
+	// GeoFence — зона допустимых координат геокодирования в формате
+	// "minLat,minLon,maxLat,maxLon". Пустое значение — без ограничений.
+	GeoFence string
+
+	CacheTTL             time.Duration
+	CacheCleanupInterval time.Duration
+
+	// CacheCleanupBatch — сколько записей кэша в памяти проверяется под одной
+	// блокировкой при фоновой очистке. 0 — размер по умолчанию.
+	CacheCleanupBatch int
+
+	// CacheBackend — реализация кэша: "memory" (по умолчанию) или "postgres".
+	CacheBackend string
+
+	// CacheTTLJitterPct — случайный разброс TTL кэша в процентах (±N%),
+	// чтобы записи одной волны не истекали одновременно. 0 — без разброса.
+	CacheTTLJitterPct int
+
+	// CacheMaxKeyLen — предел длины ключа кэша; более длинные ключи
+	// заменяются хэшем. 0 — предел по умолчанию.
+	CacheMaxKeyLen int
+
+	// UserCacheTTL — TTL кэша чтения пользователей; 0 выключает кэш.
+	UserCacheTTL time.Duration
+
+	// RunMigrations — применять миграции при старте. Выключается на
+	// репликах, которым нельзя менять схему.
+	RunMigrations bool
+
+	PprofDir string
+	HugoDir  string
+
+	// PprofRetention — как долго хранить снятые профили; более старые
+	// файлы удаляются при снятии нового профиля.
+	PprofRetention time.Duration
+
+	// PprofMaxDuration — максимальная длительность CPU-профиля и
+	// трассировки, запрошенная клиентом.
+	PprofMaxDuration time.Duration
+
+	// SMTPHost и далее — настройки исходящей почты. Пустой SMTPHost
+	// означает, что письма только пишутся в журнал.
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// SwaggerUser и SwaggerPassword — basic-auth для /swagger/*.
+	// Если не заданы, документация открыта.
+	SwaggerUser     string
+	SwaggerPassword string
+
+	// HTTPSlowThreshold — порог, после которого запрос логируется как медленный.
+	HTTPSlowThreshold time.Duration
+
+	// MaxConcurrentRequests — максимум одновременно обрабатываемых запросов;
+	// 0 отключает ограничение.
+	MaxConcurrentRequests int
+
+	// HSTSEnabled — отдавать заголовок Strict-Transport-Security.
+	// Включается только когда сервис доступен по TLS.
+	HSTSEnabled bool
+
+	// MaintenanceMode — стартовое состояние режима обслуживания.
+	MaintenanceMode bool
+
+	// StrictAccept — отклонять запросы, не принимающие application/json.
+	StrictAccept bool
+
+	// MaxPageSize — максимальный размер страницы в списочных ручках.
+	MaxPageSize int
+
+	// AdminEmails — email-адреса пользователей с правами администратора.
+	AdminEmails []string
+}
+
+// redact заменяет непустое секретное значение заглушкой, чтобы оно
+// не попало в журнал.
+func redact(v string) string {
+	if v == "" {
+		return ""
+	}
+	return "[redacted]"
+}
+
+// LogFields возвращает эффективную конфигурацию парами ключ-значение для
+// стартового лога. Секреты (пароли, ключи, JWT-секрет) заменяются заглушкой.
+func (c *Config) LogFields() []any {
+	return []any{
+		"listen_addr", c.ListenAddr,
+		"public_base_url", c.PublicBaseURL,
+		"db_host", c.DBHost,
+		"db_port", c.DBPort,
+		"db_user", c.DBUser,
+		"db_password", redact(c.DBPassword),
+		"db_name", c.DBName,
+		"db_query_timeout", c.DBQueryTimeout,
+		"db_slow_query_threshold", c.DBSlowQueryThreshold,
+		"jwt_secret", redact(c.JWTSecret),
+		"jwt_old_secrets", len(c.JWTOldSecrets),
+		"jwt_issuer", c.JWTIssuer,
+		"jwt_audience", c.JWTAudience,
+		"token_ttl", c.TokenTTL,
+		"request_timeout", c.RequestTimeout,
+		"pretty_json", c.PrettyJSON,
+		"dadata_api_key", redact(c.DadataAPIKey),
+		"dadata_secret_key", redact(c.DadataSecretKey),
+		"dadata_base_url", c.DadataBaseURL,
+		"geo_provider", c.GeoProvider,
+		"nominatim_base_url", c.NominatimBaseURL,
+		"geo_user_agent", c.GeoUserAgent,
+		"geo_debug", c.GeoDebug,
+		"geo_max_results", c.GeoMaxResults,
+		"geo_fence", c.GeoFence,
+		"cache_backend", c.CacheBackend,
+		"cache_ttl", c.CacheTTL,
+		"cache_cleanup_interval", c.CacheCleanupInterval,
+		"cache_cleanup_batch", c.CacheCleanupBatch,
+		"cache_ttl_jitter_pct", c.CacheTTLJitterPct,
+		"cache_max_key_len", c.CacheMaxKeyLen,
+		"user_cache_ttl", c.UserCacheTTL,
+		"run_migrations", c.RunMigrations,
+		"pprof_dir", c.PprofDir,
+		"pprof_retention", c.PprofRetention,
+		"pprof_max_duration", c.PprofMaxDuration,
+		"hugo_dir", c.HugoDir,
+		"smtp_host", c.SMTPHost,
+		"smtp_port", c.SMTPPort,
+		"smtp_password", redact(c.SMTPPassword),
+		"swagger_password", redact(c.SwaggerPassword),
+		"http_slow_threshold", c.HTTPSlowThreshold,
+		"max_concurrent_requests", c.MaxConcurrentRequests,
+		"hsts_enabled", c.HSTSEnabled,
+		"maintenance_mode", c.MaintenanceMode,
+		"strict_accept", c.StrictAccept,
+		"max_page_size", c.MaxPageSize,
+	}
+}
+
+// maskSecret заменяет непустой секрет на «***» для отдачи наружу.
+func maskSecret(v string) string {
+	if v == "" {
+		return ""
+	}
+	return "***"
+}
+
+// Redacted возвращает копию конфигурации с замаскированными секретами —
+// её можно безопасно отдавать в административных ручках.
+func (c *Config) Redacted() Config {
+	out := *c
+	out.DBPassword = maskSecret(out.DBPassword)
+	out.JWTSecret = maskSecret(out.JWTSecret)
+	out.JWTOldSecrets = make([]string, len(c.JWTOldSecrets))
+	for i, s := range c.JWTOldSecrets {
+		out.JWTOldSecrets[i] = maskSecret(s)
+	}
+	out.DadataAPIKey = maskSecret(out.DadataAPIKey)
+	out.DadataSecretKey = maskSecret(out.DadataSecretKey)
+	out.SMTPPassword = maskSecret(out.SMTPPassword)
+	out.SwaggerPassword = maskSecret(out.SwaggerPassword)
+	return out
+}
+
+// IsAdmin сообщает, входит ли email в список администраторов.
+func (c *Config) IsAdmin(email string) bool {
+	for _, admin := range c.AdminEmails {
+		if admin == email {
+			return true
+		}
+	}
+	return false
+}
+
+// Load читает конфигурацию из окружения, подставляя значения по умолчанию.
+func Load() *Config {
+	return &Config{
+		ListenAddr: getEnv("LISTEN_ADDR", ":8080"),
+
+		PublicBaseURL: getEnv("PUBLIC_BASE_URL", ""),
+
+		DBHost:     getEnv("DB_HOST", "localhost"),
+		DBPort:     getEnv("DB_PORT", "5432"),
+		DBUser:     getEnv("DB_USER", "postgres"),
+		DBPassword: getSecret("DB_PASSWORD", "postgres"),
+		DBName:     getEnv("DB_NAME", "geoservis"),
+
+		DBQueryTimeout: getDuration("DB_QUERY_TIMEOUT", 3*time.Second),
+
+		DBSlowQueryThreshold: time.Duration(getInt("DB_SLOW_QUERY_MS", 200)) * time.Millisecond,
+
+		JWTSecret: getSecret("JWT_SECRET", "secret"),
+
+		JWTOldSecrets: getList("JWT_OLD_SECRETS"),
+
+		JWTIssuer:   getEnv("JWT_ISSUER", ""),
+		JWTAudience: getEnv("JWT_AUDIENCE", ""),
+
+		TokenTTL: getDuration("TOKEN_TTL", time.Hour),
+
+		RequestTimeout: getDuration("REQUEST_TIMEOUT", 30*time.Second),
+
+		PrettyJSON: getBool("PRETTY_JSON", false),
+
+		DadataAPIKey:    getSecret("DADATA_API_KEY", ""),
+		DadataSecretKey: getSecret("DADATA_SECRET_KEY", ""),
+
+		DadataBaseURL: getEnv("DADATA_BASE_URL", ""),
+
+		GeoProvider: getEnv("GEO_PROVIDER", "dadata"),
+
+		NominatimBaseURL: getEnv("NOMINATIM_BASE_URL", ""),
+
+		GeoUserAgent: getEnv("GEO_USER_AGENT", "geoservice/"+version.Version),
+
+		GeoDebug: getBool("GEO_DEBUG", false),
+
+		GeoMaxResults: getInt("GEO_MAX_RESULTS", 0),
+
+		GeoFence: getEnv("GEO_FENCE", ""),
+
+		CacheTTL:             getDuration("CACHE_TTL", 10*time.Minute),
+		CacheCleanupInterval: getDuration("CACHE_CLEANUP_INTERVAL", time.Minute),
+
+		CacheCleanupBatch: getInt("CACHE_CLEANUP_BATCH", 0),
+
+		CacheBackend: getEnv("CACHE_BACKEND", "memory"),
+
+		CacheTTLJitterPct: getInt("CACHE_TTL_JITTER_PCT", 0),
+
+		CacheMaxKeyLen: getInt("CACHE_MAX_KEY_LEN", 0),
+
+		UserCacheTTL: getDuration("USER_CACHE_TTL", 0),
+
+		RunMigrations: getBool("RUN_MIGRATIONS", true),
+
+		PprofDir: getEnv("PPROF_DIR", "./pprof"),
+		HugoDir:  getEnv("HUGO_DIR", "/app/static"),
+
+		PprofRetention: getDuration("PPROF_RETENTION", 7*24*time.Hour),
+
+		PprofMaxDuration: time.Duration(getInt("PPROF_MAX_DURATION_MS", 60000)) * time.Millisecond,
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getSecret("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", "noreply@geoservis.local"),
+
+		SwaggerUser:     getEnv("SWAGGER_USER", ""),
+		SwaggerPassword: getSecret("SWAGGER_PASSWORD", ""),
+
+		HTTPSlowThreshold: time.Duration(getInt("HTTP_SLOW_MS", 1000)) * time.Millisecond,
+
+		MaxConcurrentRequests: getInt("MAX_CONCURRENT_REQUESTS", 0),
+
+		HSTSEnabled: getBool("HSTS_ENABLED", false),
+
+		MaintenanceMode: getBool("MAINTENANCE_MODE", false),
+
+		StrictAccept: getBool("STRICT_ACCEPT", false),
+
+		MaxPageSize: getInt("MAX_PAGE_SIZE", 100),
+
+		AdminEmails: getList("ADMIN_EMAILS"),
+	}
+}
+
+func getEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// getSecret читает секрет по схеме docker secrets: если задана переменная
+// KEY_FILE, значение берётся из указанного файла (без завершающего перевода
+// строки) и имеет приоритет над обычной переменной KEY. Если файл прочитать
+// не удалось, используется обычная переменная.
+func getSecret(key, def string) string {
+	if path := os.Getenv(key + "_FILE"); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			return strings.TrimRight(string(data), "\r\n")
+		}
+	}
+	return getEnv(key, def)
+}
+
+func getList(key string) []string {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	parts := strings.Split(v, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func getInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+func getBool(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+func getDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return d
+	}
+	if ms, err := strconv.Atoi(v); err == nil {
+		return time.Duration(ms) * time.Millisecond
+	}
+	return def
+}
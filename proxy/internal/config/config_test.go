@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogFields_RedactsSecrets(t *testing.T) {
+	cfg := &Config{
+		ListenAddr:      ":8080",
+		DBHost:          "db.internal",
+		DBPassword:      "super-db-password",
+		DBName:          "geoservis",
+		JWTSecret:       "super-jwt-secret",
+		DadataAPIKey:    "dadata-key",
+		DadataSecretKey: "dadata-secret",
+		SMTPPassword:    "smtp-password",
+		SwaggerPassword: "swagger-password",
+		CacheBackend:    "memory",
+	}
+
+	out := fmt.Sprint(cfg.LogFields()...)
+
+	for _, secret := range []string{
+		"super-db-password",
+		"super-jwt-secret",
+		"dadata-key",
+		"dadata-secret",
+		"smtp-password",
+		"swagger-password",
+	} {
+		if strings.Contains(out, secret) {
+			t.Errorf("log fields leak secret %q", secret)
+		}
+	}
+
+	for _, want := range []string{":8080", "db.internal", "geoservis", "memory"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log fields missing %q", want)
+		}
+	}
+}
+
+func TestGetSecret_FromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("file-secret\n"), 0o600); err != nil {
+		t.Fatalf("write secret file: %v", err)
+	}
+	t.Setenv("TEST_SECRET_FILE", path)
+
+	if got := getSecret("TEST_SECRET", "default"); got != "file-secret" {
+		t.Errorf("got %q, want file-secret without trailing newline", got)
+	}
+}
+
+func TestGetSecret_FileWinsOverEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("from-file"), 0o600); err != nil {
+		t.Fatalf("write secret file: %v", err)
+	}
+	t.Setenv("TEST_SECRET", "from-env")
+	t.Setenv("TEST_SECRET_FILE", path)
+
+	if got := getSecret("TEST_SECRET", "default"); got != "from-file" {
+		t.Errorf("got %q, want value from file", got)
+	}
+}
+
+func TestGetSecret_FallsBackToEnv(t *testing.T) {
+	t.Setenv("TEST_SECRET", "from-env")
+
+	if got := getSecret("TEST_SECRET", "default"); got != "from-env" {
+		t.Errorf("got %q, want from-env", got)
+	}
+}
+
+func TestGetSecret_UnreadableFileFallsBack(t *testing.T) {
+	t.Setenv("TEST_SECRET", "from-env")
+	t.Setenv("TEST_SECRET_FILE", filepath.Join(t.TempDir(), "no-such-file"))
+
+	if got := getSecret("TEST_SECRET", "default"); got != "from-env" {
+		t.Errorf("got %q, want fallback to env", got)
+	}
+}
+
+func TestRedact_EmptyStaysEmpty(t *testing.T) {
+	if got := redact(""); got != "" {
+		t.Errorf("redact(\"\") = %q, want empty", got)
+	}
+	if got := redact("x"); got != "[redacted]" {
+		t.Errorf("redact(\"x\") = %q, want [redacted]", got)
+	}
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/config"
+)
+
+func TestRouter_StrictAccept(t *testing.T) {
+	router, _ := newTestRouterWith(t, func(cfg *config.Config) {
+		cfg.StrictAccept = true
+	})
+	token := testToken(t, "test-secret")
+
+	t.Run("acceptable", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got %d, want 200", rec.Code)
+		}
+	})
+
+	t.Run("not acceptable", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "text/html")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotAcceptable {
+			t.Errorf("got %d, want 406", rec.Code)
+		}
+	})
+}
@@ -0,0 +1,271 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/cache"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/config"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/middleware"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/repository"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/responder"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/service"
+)
+
+// MaintenanceRequest — тело запроса переключения режима обслуживания.
+type MaintenanceRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// AdminController — служебные ручки для администраторов.
+type AdminController struct {
+	maintenance *middleware.Maintenance
+	cache       cache.Cache
+	users       *service.UserService
+	cfg         *config.Config
+	logger      *slog.Logger
+	responder   *responder.JSONResponder
+}
+
+// NewAdminController создаёт контроллер административных операций.
+func NewAdminController(mnt *middleware.Maintenance, c cache.Cache, users *service.UserService, cfg *config.Config, logger *slog.Logger, rsp *responder.JSONResponder) *AdminController {
+	return &AdminController{maintenance: mnt, cache: c, users: users, cfg: cfg, logger: logger, responder: rsp}
+}
+
+// requireAdmin проверяет, что запрос сделан администратором.
+func (c *AdminController) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	email, _ := middleware.EmailFromContext(r.Context())
+	if !c.cfg.IsAdmin(email) {
+		c.responder.Error(w, http.StatusForbidden, "admin access required")
+		return false
+	}
+	return true
+}
+
+// MemStatsResponse — срез статистики памяти после сборки мусора.
+type MemStatsResponse struct {
+	HeapAllocBytes uint64 `json:"heap_alloc_bytes"`
+	HeapSysBytes   uint64 `json:"heap_sys_bytes"`
+	SysBytes       uint64 `json:"sys_bytes"`
+	NumGC          uint32 `json:"num_gc"`
+}
+
+// RunGC принудительно запускает сборку мусора и возвращает статистику памяти.
+//
+// @Summary      Запустить GC и вернуть статистику памяти
+// @Tags         admin
+// @Produce      json
+// @Success      200 {object} MemStatsResponse
+// @Failure      403 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/admin/gc [post]
+func (c *AdminController) RunGC(w http.ResponseWriter, r *http.Request) {
+	if !c.requireAdmin(w, r) {
+		return
+	}
+
+	runtime.GC()
+	debug.FreeOSMemory()
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	c.responder.Respond(w, http.StatusOK, MemStatsResponse{
+		HeapAllocBytes: ms.HeapAlloc,
+		HeapSysBytes:   ms.HeapSys,
+		SysBytes:       ms.Sys,
+		NumGC:          ms.NumGC,
+	})
+}
+
+// FlushCache полностью очищает кэш геосервиса.
+//
+// @Summary      Сбросить кэш
+// @Tags         admin
+// @Produce      json
+// @Success      200 {object} responder.ErrorResponse
+// @Failure      403 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/admin/cache/flush [post]
+func (c *AdminController) FlushCache(w http.ResponseWriter, r *http.Request) {
+	if !c.requireAdmin(w, r) {
+		return
+	}
+
+	c.cache.Clear()
+	c.responder.Respond(w, http.StatusOK, map[string]string{"status": "cache flushed"})
+}
+
+// expiryCache — необязательная возможность кэша отдавать момент истечения
+// записи; из реализаций её поддерживает кэш в памяти.
+type expiryCache interface {
+	GetWithExpiry(key string) (interface{}, time.Time, bool)
+}
+
+// CacheEntryResponse — состояние записи кэша для отладки.
+type CacheEntryResponse struct {
+	Key    string `json:"key"`
+	Exists bool   `json:"exists"`
+	// TTLRemaining — оставшееся время жизни записи; пусто, если записи нет.
+	TTLRemaining string `json:"ttl_remaining,omitempty"`
+}
+
+// CacheEntry возвращает, существует ли запись кэша, и её оставшийся TTL —
+// для отладки поведения кэширования.
+//
+// @Summary      Состояние записи кэша
+// @Tags         admin
+// @Produce      json
+// @Param        key query string true "Ключ кэша"
+// @Success      200 {object} CacheEntryResponse
+// @Failure      400 {object} responder.ErrorResponse
+// @Failure      403 {object} responder.ErrorResponse
+// @Failure      501 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/admin/cache/entry [get]
+func (c *AdminController) CacheEntry(w http.ResponseWriter, r *http.Request) {
+	if !c.requireAdmin(w, r) {
+		return
+	}
+
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		c.responder.Error(w, http.StatusBadRequest, "key query parameter is required")
+		return
+	}
+
+	ec, ok := c.cache.(expiryCache)
+	if !ok {
+		c.responder.Error(w, http.StatusNotImplemented, "cache backend does not expose entry expiry")
+		return
+	}
+
+	resp := CacheEntryResponse{Key: key}
+	if _, expiresAt, ok := ec.GetWithExpiry(key); ok {
+		resp.Exists = true
+		resp.TTLRemaining = time.Until(expiresAt).Round(time.Millisecond).String()
+	}
+	c.responder.Respond(w, http.StatusOK, resp)
+}
+
+// ShowConfig возвращает эффективную конфигурацию с замаскированными
+// секретами, чтобы оператор мог сверить её с ожидаемой.
+//
+// @Summary      Текущая конфигурация сервиса
+// @Tags         admin
+// @Produce      json
+// @Success      200 {object} config.Config
+// @Failure      403 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/admin/config [get]
+func (c *AdminController) ShowConfig(w http.ResponseWriter, r *http.Request) {
+	if !c.requireAdmin(w, r) {
+		return
+	}
+
+	c.responder.Respond(w, http.StatusOK, c.cfg.Redacted())
+}
+
+// ImpersonateRequest — тело запроса выдачи токена от имени пользователя.
+type ImpersonateRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// impersonateTokenTTL — срок жизни токена имперсонизации. Токен нужен для
+// разового воспроизведения проблемы пользователя, поэтому живёт заметно
+// меньше обычного.
+const impersonateTokenTTL = 15 * time.Minute
+
+// Impersonate выдаёт короткоживущий JWT от имени указанного пользователя —
+// для разбора обращений в поддержку. Клейм act фиксирует администратора,
+// выпустившего токен; каждая выдача записывается в журнал.
+//
+// @Summary      Выдать токен от имени пользователя
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request body ImpersonateRequest true "Email пользователя"
+// @Success      200 {object} TokenResponse
+// @Failure      400 {object} responder.ErrorResponse
+// @Failure      403 {object} responder.ErrorResponse
+// @Failure      404 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/admin/impersonate [post]
+func (c *AdminController) Impersonate(w http.ResponseWriter, r *http.Request) {
+	if !c.requireAdmin(w, r) {
+		return
+	}
+
+	var req ImpersonateRequest
+	if !c.responder.DecodeAndValidate(w, r, &req) {
+		return
+	}
+
+	user, err := c.users.GetUserByEmail(r.Context(), req.Email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.responder.Error(w, http.StatusNotFound, "user not found")
+			return
+		}
+		c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	adminEmail, _ := middleware.EmailFromContext(r.Context())
+	claims := jwt.MapClaims{
+		"email": user.Email,
+		"act":   adminEmail,
+		"exp":   time.Now().Add(impersonateTokenTTL).Unix(),
+	}
+	if c.cfg.JWTIssuer != "" {
+		claims["iss"] = c.cfg.JWTIssuer
+	}
+	if c.cfg.JWTAudience != "" {
+		claims["aud"] = c.cfg.JWTAudience
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(c.cfg.JWTSecret))
+	if err != nil {
+		c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.logger.Info("impersonation token issued",
+		"admin", adminEmail,
+		"target", user.Email,
+		"ttl", impersonateTokenTTL)
+
+	c.responder.Respond(w, http.StatusOK, TokenResponse{Token: signed})
+}
+
+// SetMaintenance включает или выключает режим обслуживания.
+//
+// @Summary      Переключить режим обслуживания
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request body MaintenanceRequest true "Новое состояние"
+// @Success      200 {object} MaintenanceRequest
+// @Failure      403 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/admin/maintenance [post]
+func (c *AdminController) SetMaintenance(w http.ResponseWriter, r *http.Request) {
+	if !c.requireAdmin(w, r) {
+		return
+	}
+
+	var req MaintenanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		c.responder.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	c.maintenance.SetEnabled(req.Enabled)
+	c.responder.Respond(w, http.StatusOK, MaintenanceRequest{Enabled: c.maintenance.Enabled()})
+}
@@ -0,0 +1,327 @@
+package controller
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/cache"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/config"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/middleware"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/responder"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+	router, _ := newTestRouterWith(t, func(cfg *config.Config) {
+		cfg.MaintenanceMode = true
+	})
+	token := testToken(t, "test-secret")
+
+	t.Run("GET passes", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got %d, want 200", rec.Code)
+		}
+	})
+
+	t.Run("POST gets 503 with Retry-After", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/address/search",
+			strings.NewReader(`{"query":"Москва"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("got %d, want 503", rec.Code)
+		}
+		if rec.Header().Get("Retry-After") == "" {
+			t.Error("503 response must carry Retry-After")
+		}
+	})
+
+	t.Run("admin can switch it off at runtime", func(t *testing.T) {
+		adminToken := testTokenFor(t, "test-secret", "admin@example.com")
+
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/maintenance",
+			strings.NewReader(`{"enabled":false}`))
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+		}
+
+		req = httptest.NewRequest(http.MethodPost, "/api/address/search",
+			strings.NewReader(`{"query":"Москва"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got %d, want 200 after maintenance disabled", rec.Code)
+		}
+	})
+}
+
+func TestRunGC(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/gc", nil)
+	req.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", "admin@example.com"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+
+	var stats map[string]float64
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("unmarshal body: %v", err)
+	}
+	for _, field := range []string{"heap_alloc_bytes", "heap_sys_bytes", "sys_bytes", "num_gc"} {
+		if _, ok := stats[field]; !ok {
+			t.Errorf("field %q missing from response", field)
+		}
+	}
+	if stats["num_gc"] < 1 {
+		t.Error("num_gc must be at least 1 after a forced GC")
+	}
+}
+
+func TestFlushCache(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	t.Run("admin flushes cache", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/cache/flush", nil)
+		req.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", "admin@example.com"))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("non-admin gets 403", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/cache/flush", nil)
+		req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("got %d, want 403", rec.Code)
+		}
+	})
+}
+
+func TestMaintenanceToggle_NonAdmin(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/maintenance",
+		strings.NewReader(`{"enabled":true}`))
+	req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("got %d, want 403 for non-admin", rec.Code)
+	}
+}
+
+func TestShowConfig_RedactsSecrets(t *testing.T) {
+	router, _ := newTestRouterWith(t, func(cfg *config.Config) {
+		cfg.DBPassword = "super-db-password"
+		cfg.DadataAPIKey = "dadata-api-key"
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/config", nil)
+	req.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", "admin@example.com"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+
+	body := rec.Body.String()
+	for _, secret := range []string{"super-db-password", "dadata-api-key", "test-secret"} {
+		if strings.Contains(body, secret) {
+			t.Errorf("config response leaks secret %q", secret)
+		}
+	}
+	if !strings.Contains(body, "***") {
+		t.Error("masked secrets must appear as ***")
+	}
+	if !strings.Contains(body, "memory") {
+		t.Error("non-secret values must be visible")
+	}
+}
+
+func TestShowConfig_NonAdmin(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/config", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("got %d, want 403", rec.Code)
+	}
+}
+
+func TestImpersonate(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 1)
+	adminToken := testTokenFor(t, "test-secret", "admin@example.com")
+
+	issue := func(t *testing.T, token, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/impersonate",
+			strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("issued token authenticates as target with actor claim", func(t *testing.T) {
+		rec := issue(t, adminToken, `{"email":"user0@example.com"}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+		}
+		var resp TokenResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("unmarshal response: %v", err)
+		}
+
+		token, err := jwt.Parse(resp.Token, func(*jwt.Token) (interface{}, error) {
+			return []byte("test-secret"), nil
+		})
+		if err != nil || !token.Valid {
+			t.Fatalf("issued token must verify with the active secret: %v", err)
+		}
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			t.Fatalf("unexpected claims type %T", token.Claims)
+		}
+		if got := claims["email"]; got != "user0@example.com" {
+			t.Errorf("got email claim %v, want user0@example.com", got)
+		}
+		if got := claims["act"]; got != "admin@example.com" {
+			t.Errorf("got act claim %v, want admin@example.com", got)
+		}
+		exp, ok := claims["exp"].(float64)
+		if !ok || time.Unix(int64(exp), 0).After(time.Now().Add(impersonateTokenTTL+time.Minute)) {
+			t.Errorf("got exp claim %v, want a short-lived token", claims["exp"])
+		}
+
+		// Выданный токен проходит AuthMiddleware как обычный.
+		req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+		req.Header.Set("Authorization", "Bearer "+resp.Token)
+		authRec := httptest.NewRecorder()
+		router.ServeHTTP(authRec, req)
+		if authRec.Code != http.StatusOK {
+			t.Errorf("issued token must authenticate: got %d, want 200 (body: %s)",
+				authRec.Code, authRec.Body.String())
+		}
+	})
+
+	t.Run("unknown user gets 404", func(t *testing.T) {
+		if rec := issue(t, adminToken, `{"email":"missing@example.com"}`); rec.Code != http.StatusNotFound {
+			t.Errorf("got %d, want 404", rec.Code)
+		}
+	})
+
+	t.Run("invalid email gets 400", func(t *testing.T) {
+		if rec := issue(t, adminToken, `{"email":"not-an-email"}`); rec.Code != http.StatusBadRequest {
+			t.Errorf("got %d, want 400", rec.Code)
+		}
+	})
+
+	t.Run("non-admin gets 403", func(t *testing.T) {
+		if rec := issue(t, testToken(t, "test-secret"), `{"email":"user0@example.com"}`); rec.Code != http.StatusForbidden {
+			t.Errorf("got %d, want 403", rec.Code)
+		}
+	})
+}
+
+func TestCacheEntry(t *testing.T) {
+	cfg := testConfig()
+	logger := slog.New(slog.NewTextHandler(httptest.NewRecorder(), nil))
+	rsp := responder.NewJSONResponder(logger)
+	geoCache := cache.NewInMemoryCache(time.Minute)
+	t.Cleanup(geoCache.Close)
+	geoCache.Set("geocode:55.75:37.61", "cached", time.Minute)
+
+	ctrl := NewAdminController(middleware.NewMaintenance(false), geoCache, nil, cfg, logger, rsp)
+	router := chi.NewRouter()
+	router.Use(middleware.AuthMiddleware(cfg.JWTSecret, nil, "", ""))
+	router.Get("/api/admin/cache/entry", ctrl.CacheEntry)
+
+	get := func(t *testing.T, email, query string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/cache/entry"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", email))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("existing key reports remaining TTL", func(t *testing.T) {
+		rec := get(t, "admin@example.com", "?key=geocode:55.75:37.61")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+		}
+		var resp CacheEntryResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("unmarshal response: %v", err)
+		}
+		if !resp.Exists {
+			t.Error("entry must exist")
+		}
+		ttl, err := time.ParseDuration(resp.TTLRemaining)
+		if err != nil || ttl <= 0 {
+			t.Errorf("got ttl_remaining %q, want a positive duration", resp.TTLRemaining)
+		}
+	})
+
+	t.Run("missing key reports absence", func(t *testing.T) {
+		rec := get(t, "admin@example.com", "?key=no-such-key")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got %d, want 200", rec.Code)
+		}
+		var resp CacheEntryResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("unmarshal response: %v", err)
+		}
+		if resp.Exists || resp.TTLRemaining != "" {
+			t.Errorf("missing key must report exists=false without TTL: %+v", resp)
+		}
+	})
+
+	t.Run("key parameter required", func(t *testing.T) {
+		if rec := get(t, "admin@example.com", ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("got %d, want 400", rec.Code)
+		}
+	})
+
+	t.Run("non-admin gets 403", func(t *testing.T) {
+		if rec := get(t, "test@example.com", "?key=x"); rec.Code != http.StatusForbidden {
+			t.Errorf("got %d, want 403", rec.Code)
+		}
+	})
+}
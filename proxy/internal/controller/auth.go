@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/config"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/metrics"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/repository"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/responder"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/service"
+)
+
+// RegisterRequest — тело запроса регистрации.
+type RegisterRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// LoginRequest — тело запроса входа.
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// TokenResponse — ответ с выданным JWT.
+type TokenResponse struct {
+	Token string `json:"token"`
+}
+
+// AuthController — обработчики регистрации и входа.
+type AuthController struct {
+	users     *service.UserService
+	cfg       *config.Config
+	responder *responder.JSONResponder
+}
+
+// NewAuthController создаёт контроллер авторизации.
+func NewAuthController(users *service.UserService, cfg *config.Config, rsp *responder.JSONResponder) *AuthController {
+	return &AuthController{users: users, cfg: cfg, responder: rsp}
+}
+
+// RegisterHandler регистрирует нового пользователя.
+//
+// @Summary      Регистрация пользователя
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body RegisterRequest true "Учётные данные"
+// @Success      201 {object} entity.User
+// @Failure      400 {object} responder.ErrorResponse
+// @Failure      409 {object} responder.ErrorResponse
+// @Router       /api/register [post]
+func (c *AuthController) RegisterHandler(w http.ResponseWriter, r *http.Request) {
+	var req RegisterRequest
+	if !c.responder.DecodeAndValidate(w, r, &req) {
+		metrics.UserRegistrationErrorsTotal.WithLabelValues("validation").Inc()
+		return
+	}
+
+	user, err := c.users.RegisterUser(r.Context(), req.Email, req.Password)
+	if err != nil {
+		if err == repository.ErrAlreadyExists {
+			metrics.UserRegistrationErrorsTotal.WithLabelValues("duplicate").Inc()
+			c.responder.Error(w, http.StatusConflict, "user already exists")
+			return
+		}
+		metrics.UserRegistrationErrorsTotal.WithLabelValues("internal").Inc()
+		c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	metrics.UserRegistrationsTotal.Inc()
+
+	c.responder.Respond(w, http.StatusCreated, user)
+}
+
+// LoginHandler проверяет учётные данные и выдаёт JWT.
+//
+// @Summary      Вход пользователя
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body LoginRequest true "Учётные данные"
+// @Success      200 {object} TokenResponse
+// @Failure      400 {object} responder.ErrorResponse
+// @Failure      403 {object} responder.ErrorResponse
+// @Failure      500 {object} responder.ErrorResponse
+// @Router       /api/login [post]
+func (c *AuthController) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	var req LoginRequest
+	if !c.responder.DecodeAndValidate(w, r, &req) {
+		return
+	}
+
+	user, err := c.users.Login(r.Context(), req.Email, req.Password)
+	if err != nil {
+		// Инфраструктурный сбой (например, недоступная база) — не отказ
+		// во входе: клиент получает 500, метрика — отдельную метку.
+		if !errors.Is(err, service.ErrInvalidCredentials) {
+			metrics.AuthLoginAttemptsTotal.WithLabelValues("internal").Inc()
+			c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		// Результат фиксируется в метриках, но клиенту причина не
+		// раскрывается — ответ одинаков для обоих случаев.
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			metrics.AuthLoginAttemptsTotal.WithLabelValues("user_not_found").Inc()
+		case errors.Is(err, service.ErrInvalidPassword):
+			metrics.AuthLoginAttemptsTotal.WithLabelValues("invalid_password").Inc()
+		}
+		c.responder.Error(w, http.StatusForbidden, "invalid credentials")
+		return
+	}
+	metrics.AuthLoginAttemptsTotal.WithLabelValues("success").Inc()
+
+	claims := jwt.MapClaims{
+		"email": user.Email,
+		"exp":   time.Now().Add(c.cfg.TokenTTL).Unix(),
+	}
+	if c.cfg.JWTIssuer != "" {
+		claims["iss"] = c.cfg.JWTIssuer
+	}
+	if c.cfg.JWTAudience != "" {
+		claims["aud"] = c.cfg.JWTAudience
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(c.cfg.JWTSecret))
+	if err != nil {
+		c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.responder.Respond(w, http.StatusOK, TokenResponse{Token: signed})
+}
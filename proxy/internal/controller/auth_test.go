@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/entity"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/metrics"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/responder"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/service"
+)
+
+func TestRegister_ResponseCarriesTimestamps(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/register",
+		strings.NewReader(`{"email":"new@example.com","password":"secret-password"}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("got %d, want 201 (body: %s)", rec.Code, rec.Body.String())
+	}
+
+	var user entity.User
+	if err := json.Unmarshal(rec.Body.Bytes(), &user); err != nil {
+		t.Fatalf("unmarshal user: %v", err)
+	}
+	if user.ID == 0 {
+		t.Error("id missing from registration response")
+	}
+	if user.Email != "new@example.com" {
+		t.Errorf("got email %q, want new@example.com", user.Email)
+	}
+	if user.CreatedAt.IsZero() || user.UpdatedAt.IsZero() {
+		t.Errorf("timestamps missing: created_at=%v updated_at=%v", user.CreatedAt, user.UpdatedAt)
+	}
+	if strings.Contains(rec.Body.String(), "password") {
+		t.Error("registration response must not expose password fields")
+	}
+}
+
+func loginAttempts(result string) float64 {
+	return testutil.ToFloat64(metrics.AuthLoginAttemptsTotal.WithLabelValues(result))
+}
+
+func TestLogin_AttemptMetrics(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	register := httptest.NewRequest(http.MethodPost, "/api/register",
+		strings.NewReader(`{"email":"metrics@example.com","password":"secret-password"}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, register)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("register: got %d, want 201", rec.Code)
+	}
+
+	successBefore := loginAttempts("success")
+	wrongPassBefore := loginAttempts("invalid_password")
+	notFoundBefore := loginAttempts("user_not_found")
+
+	for _, body := range []string{
+		`{"email":"metrics@example.com","password":"secret-password"}`,
+		`{"email":"metrics@example.com","password":"wrong-password"}`,
+		`{"email":"nobody@example.com","password":"secret-password"}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/login", strings.NewReader(body))
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if got := loginAttempts("success"); got != successBefore+1 {
+		t.Errorf("success counter = %v, want %v", got, successBefore+1)
+	}
+	if got := loginAttempts("invalid_password"); got != wrongPassBefore+1 {
+		t.Errorf("invalid_password counter = %v, want %v", got, wrongPassBefore+1)
+	}
+	if got := loginAttempts("user_not_found"); got != notFoundBefore+1 {
+		t.Errorf("user_not_found counter = %v, want %v", got, notFoundBefore+1)
+	}
+}
+
+// failingUserRepo имитирует инфраструктурный сбой при поиске по email.
+type failingUserRepo struct {
+	*fakeUserRepo
+}
+
+func (f *failingUserRepo) GetByEmail(context.Context, string) (*entity.User, error) {
+	return nil, errors.New("db is down")
+}
+
+func TestLogin_InfrastructureFailureIs500(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(httptest.NewRecorder(), nil))
+	rsp := responder.NewJSONResponder(logger)
+	users := service.NewUserService(&failingUserRepo{newFakeUserRepo()}, nil, logger)
+	ctrl := NewAuthController(users, testConfig(), rsp)
+
+	before := loginAttempts("internal")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/login",
+		strings.NewReader(`{"email":"someone@example.com","password":"secret-password"}`))
+	rec := httptest.NewRecorder()
+	ctrl.LoginHandler(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("got %d, want 500 for infrastructure failure (body: %s)",
+			rec.Code, rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "invalid credentials") {
+		t.Error("infrastructure failure must not look like rejected credentials")
+	}
+	if got := loginAttempts("internal"); got != before+1 {
+		t.Errorf("internal counter = %v, want %v", got, before+1)
+	}
+}
+
+func TestRegister_Metrics(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	successBefore := testutil.ToFloat64(metrics.UserRegistrationsTotal)
+	duplicateBefore := testutil.ToFloat64(metrics.UserRegistrationErrorsTotal.WithLabelValues("duplicate"))
+
+	body := `{"email":"growth@example.com","password":"secret-password"}`
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/register", strings.NewReader(body)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("got %d, want 201 (body: %s)", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/register", strings.NewReader(body)))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("got %d, want 409 for duplicate", rec.Code)
+	}
+
+	if got := testutil.ToFloat64(metrics.UserRegistrationsTotal); got != successBefore+1 {
+		t.Errorf("registrations counter = %v, want %v", got, successBefore+1)
+	}
+	if got := testutil.ToFloat64(metrics.UserRegistrationErrorsTotal.WithLabelValues("duplicate")); got != duplicateBefore+1 {
+		t.Errorf("duplicate counter = %v, want %v", got, duplicateBefore+1)
+	}
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/entity"
+)
+
+func postJSON(t *testing.T, router *chi.Mux, path, body, token string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestEmailChange_PendingCommitFlow(t *testing.T) {
+	router, repo := newTestRouter(t)
+	err := repo.Create(context.Background(), &entity.User{
+		Email:        "test@example.com",
+		PasswordHash: "hash",
+	})
+	if err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	token := testToken(t, "test-secret")
+
+	rec := postJSON(t, router, "/api/users/me/email", `{"email":"new@example.com"}`, token)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("request change: got %d, want 202 (body: %s)", rec.Code, rec.Body.String())
+	}
+
+	user := repo.users[1]
+	if user.PendingEmail == nil || *user.PendingEmail != "new@example.com" {
+		t.Fatalf("pending email not stored: %+v", user)
+	}
+	if user.PendingEmailToken == nil || *user.PendingEmailToken == "" {
+		t.Fatal("verification token not stored")
+	}
+	if user.Email != "test@example.com" {
+		t.Fatalf("email changed before verification: %q", user.Email)
+	}
+
+	rec = postJSON(t, router, "/api/users/me/email/verify",
+		`{"token":"`+*user.PendingEmailToken+`"}`, token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("verify: got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+	if user.Email != "new@example.com" {
+		t.Errorf("email not committed: %q", user.Email)
+	}
+	if user.PendingEmail != nil || user.PendingEmailToken != nil {
+		t.Error("pending fields not cleared after commit")
+	}
+}
+
+func TestEmailChange_InvalidToken(t *testing.T) {
+	router, repo := newTestRouter(t)
+	err := repo.Create(context.Background(), &entity.User{
+		Email:        "test@example.com",
+		PasswordHash: "hash",
+	})
+	if err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	token := testToken(t, "test-secret")
+
+	rec := postJSON(t, router, "/api/users/me/email", `{"email":"new@example.com"}`, token)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("request change: got %d, want 202", rec.Code)
+	}
+
+	rec = postJSON(t, router, "/api/users/me/email/verify", `{"token":"wrong"}`, token)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("got %d, want 400 for invalid token", rec.Code)
+	}
+	if repo.users[1].Email != "test@example.com" {
+		t.Errorf("email changed despite invalid token: %q", repo.users[1].Email)
+	}
+}
+
+func TestEmailChange_AlreadyUsedEmail(t *testing.T) {
+	router, repo := newTestRouter(t)
+	for _, email := range []string{"test@example.com", "taken@example.com"} {
+		err := repo.Create(context.Background(), &entity.User{
+			Email:        email,
+			PasswordHash: "hash",
+		})
+		if err != nil {
+			t.Fatalf("seed %s: %v", email, err)
+		}
+	}
+	token := testToken(t, "test-secret")
+
+	rec := postJSON(t, router, "/api/users/me/email", `{"email":"taken@example.com"}`, token)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("got %d, want 409 for already-used email (body: %s)", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,289 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/entity"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/responder"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/service"
+)
+
+// SearchRequest — тело запроса поиска адреса.
+type SearchRequest struct {
+	Query string `json:"query" validate:"required,min=2"`
+}
+
+// GeocodeRequest — тело запроса геокодирования по координатам.
+type GeocodeRequest struct {
+	Lat string `json:"lat" validate:"required,latitude"`
+	Lon string `json:"lon" validate:"required,longitude"`
+}
+
+// SearchResponse — ответ с найденными адресами. Source сообщает, кто дал
+// результат: имя провайдера ("dadata", "nominatim") или "cache"; пусто,
+// если сервис источник не сообщает.
+type SearchResponse struct {
+	Addresses []*entity.Address `json:"addresses"`
+	Source    string            `json:"source,omitempty"`
+}
+
+// GeoController — обработчики поиска и геокодирования адресов.
+type GeoController struct {
+	geo       service.GeoServicer
+	responder *responder.JSONResponder
+	// fence — зона допустимых координат геокодирования; nil — без ограничений.
+	fence *GeoFence
+}
+
+// NewGeoController создаёт контроллер геосервиса.
+func NewGeoController(geo service.GeoServicer, rsp *responder.JSONResponder) *GeoController {
+	return &GeoController{geo: geo, responder: rsp}
+}
+
+// WithGeoFence включает проверку координат геокодирования по зоне
+// (переменная GEO_FENCE). По умолчанию проверка выключена.
+func (c *GeoController) WithGeoFence(f *GeoFence) *GeoController {
+	c.fence = f
+	return c
+}
+
+// statusClientClosedRequest — нестандартный статус (соглашение nginx) для
+// запросов, оборванных самим клиентом.
+const statusClientClosedRequest = 499
+
+// respondGeoError переводит ошибку геосервиса в HTTP-ответ. Отмена и
+// истечение контекста запроса — не сбои сервера: обрыв клиента даёт 499,
+// дедлайн — 504, и только остальные ошибки — общий 500.
+func (c *GeoController) respondGeoError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, context.Canceled) || errors.Is(r.Context().Err(), context.Canceled):
+		c.responder.Error(w, statusClientClosedRequest, "client closed request")
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(r.Context().Err(), context.DeadlineExceeded):
+		c.responder.Error(w, http.StatusGatewayTimeout, "request timed out")
+	default:
+		c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+// AddressSearch ищет адреса по строке запроса.
+//
+// @Summary      Поиск адреса
+// @Tags         address
+// @Accept       json
+// @Produce      json
+// @Param        request body SearchRequest true "Строка поиска"
+// @Success      200 {object} SearchResponse
+// @Failure      400 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/address/search [post]
+func (c *GeoController) AddressSearch(w http.ResponseWriter, r *http.Request) {
+	var req SearchRequest
+	if !c.responder.DecodeAndValidate(w, r, &req) {
+		return
+	}
+
+	addresses, source, err := service.AddressSearchSourced(c.geo, req.Query)
+	if err != nil {
+		c.respondGeoError(w, r, err)
+		return
+	}
+
+	if wantsGeoJSON(r) {
+		w.Header().Set("Content-Type", "application/geo+json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(entity.ToGeoJSON(addresses))
+		return
+	}
+
+	c.responder.Respond(w, http.StatusOK, SearchResponse{Addresses: addresses, Source: source})
+}
+
+// BatchSearchRequest — тело пакетного поиска адресов.
+type BatchSearchRequest struct {
+	Queries []string `json:"queries" validate:"required,min=1,max=100,dive,min=2"`
+}
+
+// Статусы элементов пакетного ответа.
+const (
+	BatchStatusOK    = "ok"
+	BatchStatusError = "error"
+)
+
+// BatchSearchResult — результат одного запроса пакета: адреса при успехе
+// либо описание ошибки. Ошибка одного запроса не валит весь пакет.
+type BatchSearchResult struct {
+	Query     string            `json:"query"`
+	Addresses []*entity.Address `json:"addresses,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	Status    string            `json:"status"`
+}
+
+// BatchSearchResponse — результаты пакетного поиска в порядке запросов.
+type BatchSearchResponse struct {
+	Results []BatchSearchResult `json:"results"`
+}
+
+// AddressSearchBatch ищет адреса по нескольким строкам за один запрос.
+// Каждый элемент пакета обрабатывается независимо: сбой поиска по одной
+// строке помечается статусом error, остальные результаты не теряются.
+//
+// @Summary      Пакетный поиск адресов
+// @Tags         address
+// @Accept       json
+// @Produce      json
+// @Param        request body BatchSearchRequest true "Строки поиска (не больше 100)"
+// @Success      200 {object} BatchSearchResponse
+// @Failure      400 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/address/search/batch [post]
+func (c *GeoController) AddressSearchBatch(w http.ResponseWriter, r *http.Request) {
+	var req BatchSearchRequest
+	if !c.responder.DecodeAndValidate(w, r, &req) {
+		return
+	}
+
+	results := make([]BatchSearchResult, 0, len(req.Queries))
+	for _, query := range req.Queries {
+		res := BatchSearchResult{Query: query, Status: BatchStatusOK}
+		addresses, err := c.geo.AddressSearch(query)
+		if err != nil {
+			res.Status = BatchStatusError
+			res.Error = "search failed"
+		} else {
+			res.Addresses = addresses
+		}
+		results = append(results, res)
+	}
+
+	c.responder.Respond(w, http.StatusOK, BatchSearchResponse{Results: results})
+}
+
+// wantsGeoJSON сообщает, запросил ли клиент ответ в формате GeoJSON —
+// параметром format=geojson или заголовком Accept: application/geo+json.
+func wantsGeoJSON(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "geojson" {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/geo+json")
+}
+
+// ValidateRequest — тело запроса проверки адреса.
+type ValidateRequest struct {
+	Query string `json:"query" validate:"required,min=2"`
+}
+
+// ValidateResponse — вердикт проверки адреса и лучший найденный вариант.
+type ValidateResponse struct {
+	// Verdict — valid, partial или not_found.
+	Verdict string          `json:"verdict"`
+	Address *entity.Address `json:"address,omitempty"`
+}
+
+// Вердикты проверки адреса.
+const (
+	VerdictValid    = "valid"
+	VerdictPartial  = "partial"
+	VerdictNotFound = "not_found"
+)
+
+// addressVerdict оценивает полноту адреса: valid — есть город, улица и дом
+// с точным геокодом; partial — найден хотя бы город; иначе not_found.
+func addressVerdict(a *entity.Address) string {
+	switch {
+	case a.City != "" && a.Street != "" && a.House != "":
+		switch a.Precision {
+		case "", "exact", "near_house":
+			return VerdictValid
+		}
+		return VerdictPartial
+	case a.City != "":
+		return VerdictPartial
+	default:
+		return VerdictNotFound
+	}
+}
+
+// ValidateAddress проверяет адрес на полноту по лучшему найденному варианту.
+//
+// @Summary      Проверка адреса
+// @Tags         address
+// @Accept       json
+// @Produce      json
+// @Param        request body ValidateRequest true "Строка адреса"
+// @Success      200 {object} ValidateResponse
+// @Failure      400 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/address/validate [post]
+func (c *GeoController) ValidateAddress(w http.ResponseWriter, r *http.Request) {
+	var req ValidateRequest
+	if !c.responder.DecodeAndValidate(w, r, &req) {
+		return
+	}
+
+	addresses, err := c.geo.AddressSearch(req.Query)
+	if err != nil {
+		c.respondGeoError(w, r, err)
+		return
+	}
+	if len(addresses) == 0 {
+		c.responder.Respond(w, http.StatusOK, ValidateResponse{Verdict: VerdictNotFound})
+		return
+	}
+
+	top := addresses[0]
+	c.responder.Respond(w, http.StatusOK, ValidateResponse{
+		Verdict: addressVerdict(top),
+		Address: top,
+	})
+}
+
+// GeoCode возвращает адреса по координатам.
+//
+// @Summary      Геокодирование по координатам
+// @Tags         address
+// @Accept       json
+// @Produce      json
+// @Param        request body GeocodeRequest true "Координаты"
+// @Success      200 {object} SearchResponse
+// @Failure      400 {object} responder.ErrorResponse
+// @Failure      422 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/address/geocode [post]
+func (c *GeoController) GeoCode(w http.ResponseWriter, r *http.Request) {
+	var req GeocodeRequest
+	if !c.responder.DecodeAndValidate(w, r, &req) {
+		return
+	}
+
+	if c.fence != nil {
+		lat, latErr := strconv.ParseFloat(req.Lat, 64)
+		lon, lonErr := strconv.ParseFloat(req.Lon, 64)
+		if latErr != nil || lonErr != nil || !c.fence.Contains(lat, lon) {
+			c.responder.Error(w, http.StatusUnprocessableEntity, "coordinates outside service area")
+			return
+		}
+	}
+
+	addresses, source, err := service.GeoCodeSourced(c.geo, req.Lat, req.Lon)
+	if err != nil {
+		var rateLimited *service.ErrGeoRateLimited
+		if errors.As(err, &rateLimited) {
+			if rateLimited.RetryAfter > 0 {
+				w.Header().Set("Retry-After",
+					strconv.Itoa(int(rateLimited.RetryAfter.Round(time.Second).Seconds())))
+			}
+			c.responder.Error(w, http.StatusTooManyRequests, "upstream rate limited")
+			return
+		}
+		c.respondGeoError(w, r, err)
+		return
+	}
+
+	c.responder.Respond(w, http.StatusOK, SearchResponse{Addresses: addresses, Source: source})
+}
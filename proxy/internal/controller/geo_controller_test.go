@@ -0,0 +1,310 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/entity"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/responder"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/service"
+)
+
+func TestAddressSearch_GeoJSONFormat(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	for _, tc := range []struct {
+		name  string
+		path  string
+		setup func(*http.Request)
+	}{
+		{"query param", "/api/address/search?format=geojson", func(*http.Request) {}},
+		{"accept header", "/api/address/search", func(r *http.Request) {
+			r.Header.Set("Accept", "application/geo+json")
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.path,
+				strings.NewReader(`{"query":"Москва"}`))
+			req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+			tc.setup(req)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/geo+json" {
+				t.Errorf("got Content-Type %q, want application/geo+json", ct)
+			}
+
+			var fc entity.GeoJSONFeatureCollection
+			if err := json.Unmarshal(rec.Body.Bytes(), &fc); err != nil {
+				t.Fatalf("unmarshal GeoJSON: %v", err)
+			}
+			if fc.Type != "FeatureCollection" || len(fc.Features) == 0 {
+				t.Errorf("unexpected collection: %+v", fc)
+			}
+		})
+	}
+}
+
+func TestAddressSearch_DefaultShapeUnchanged(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/address/search",
+		strings.NewReader(`{"query":"Москва"}`))
+	req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200", rec.Code)
+	}
+
+	var resp SearchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal default response: %v", err)
+	}
+	if len(resp.Addresses) == 0 {
+		t.Error("default response must keep addresses array")
+	}
+}
+
+func TestAddressSearch_SourceField(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/address/search",
+		strings.NewReader(`{"query":"Москва"}`))
+	req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+
+	var resp SearchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if resp.Source != service.GeoSourceDadata {
+		t.Errorf("got source %q, want %q", resp.Source, service.GeoSourceDadata)
+	}
+}
+
+func TestAddressSearchBatch_MixedResults(t *testing.T) {
+	geo := &fakeGeo{
+		addresses: []*entity.Address{{City: "Москва", Street: "Тверская"}},
+		failQuery: "Сломанный запрос",
+	}
+	router, _ := newTestRouterGeo(t, nil, geo)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/address/search/batch",
+		strings.NewReader(`{"queries":["Москва Тверская","Сломанный запрос","Москва Арбат"]}`))
+	req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+
+	var resp BatchSearchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if len(resp.Results) != 3 {
+		t.Fatalf("got %d results, want 3", len(resp.Results))
+	}
+
+	for i, want := range []string{BatchStatusOK, BatchStatusError, BatchStatusOK} {
+		if resp.Results[i].Status != want {
+			t.Errorf("result %d: got status %q, want %q", i, resp.Results[i].Status, want)
+		}
+	}
+	if resp.Results[0].Query != "Москва Тверская" || len(resp.Results[0].Addresses) == 0 {
+		t.Errorf("successful result lost its addresses: %+v", resp.Results[0])
+	}
+	if resp.Results[1].Error == "" || len(resp.Results[1].Addresses) != 0 {
+		t.Errorf("failed result must carry error without addresses: %+v", resp.Results[1])
+	}
+}
+
+func TestAddressSearchBatch_EmptyBatchRejected(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/address/search/batch",
+		strings.NewReader(`{"queries":[]}`))
+	req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("got %d, want 400", rec.Code)
+	}
+}
+
+func TestValidateAddress(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		addresses   []*entity.Address
+		wantVerdict string
+	}{
+		{"complete address", []*entity.Address{
+			{City: "Москва", Street: "Тверская", House: "1", Precision: "exact"},
+		}, VerdictValid},
+		{"city only", []*entity.Address{
+			{City: "Москва"},
+		}, VerdictPartial},
+		{"imprecise geocode", []*entity.Address{
+			{City: "Москва", Street: "Тверская", House: "1", Precision: "city"},
+		}, VerdictPartial},
+		{"gibberish", nil, VerdictNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			router, _ := newTestRouterGeo(t, nil, &fakeGeo{addresses: tc.addresses})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/address/validate",
+				strings.NewReader(`{"query":"Москва Тверская 1"}`))
+			req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+			}
+
+			var resp ValidateResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("unmarshal response: %v", err)
+			}
+			if resp.Verdict != tc.wantVerdict {
+				t.Errorf("got verdict %q, want %q", resp.Verdict, tc.wantVerdict)
+			}
+		})
+	}
+}
+
+func TestGeoCode_UpstreamRateLimitPassesRetryAfter(t *testing.T) {
+	geo := &fakeGeo{err: &service.ErrGeoRateLimited{RetryAfter: 7 * time.Second}}
+	router, _ := newTestRouterGeo(t, nil, geo)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/address/geocode",
+		strings.NewReader(`{"lat":"55.75","lon":"37.61"}`))
+	req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("got %d, want 429 (body: %s)", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Retry-After"); got != "7" {
+		t.Errorf("got Retry-After %q, want 7", got)
+	}
+}
+
+func TestParseGeoFence(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		in      string
+		wantErr bool
+		wantNil bool
+	}{
+		{"empty disables fence", "", false, true},
+		{"valid box", "55.0,36.0,56.5,38.5", false, false},
+		{"spaces tolerated", " 55.0, 36.0, 56.5, 38.5 ", false, false},
+		{"too few values", "55.0,36.0,56.5", true, false},
+		{"not a number", "55.0,36.0,56.5,abc", true, false},
+		{"latitude out of range", "-91,36.0,56.5,38.5", true, false},
+		{"longitude out of range", "55.0,36.0,56.5,181", true, false},
+		{"min above max", "56.5,36.0,55.0,38.5", true, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := ParseGeoFence(tc.in)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got fence %+v", f)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (f == nil) != tc.wantNil {
+				t.Errorf("got fence %+v, want nil=%v", f, tc.wantNil)
+			}
+		})
+	}
+}
+
+func TestGeoCode_GeoFence(t *testing.T) {
+	fence := &GeoFence{MinLat: 55.0, MinLon: 36.0, MaxLat: 56.5, MaxLon: 38.5}
+
+	for _, tc := range []struct {
+		name       string
+		fence      *GeoFence
+		body       string
+		wantStatus int
+	}{
+		{"in bounds allowed", fence, `{"lat":"55.75","lon":"37.61"}`, http.StatusOK},
+		{"out of bounds rejected", fence, `{"lat":"59.93","lon":"30.31"}`, http.StatusUnprocessableEntity},
+		{"disabled fence allows anything", nil, `{"lat":"59.93","lon":"30.31"}`, http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			geo := &fakeGeo{addresses: []*entity.Address{{City: "Москва"}}}
+			logger := slog.New(slog.NewTextHandler(httptest.NewRecorder(), nil))
+			ctrl := NewGeoController(geo, responder.NewJSONResponder(logger)).WithGeoFence(tc.fence)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/address/geocode",
+				strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+			ctrl.GeoCode(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("got %d, want %d (body: %s)", rec.Code, tc.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestGeoController_ContextErrorsAreNotServerErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		err        error
+		cancelCtx  bool
+		wantStatus int
+	}{
+		{"cancelled context", context.Canceled, true, statusClientClosedRequest},
+		{"deadline exceeded", context.DeadlineExceeded, false, http.StatusGatewayTimeout},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&buf, nil))
+			ctrl := NewGeoController(&fakeGeo{err: tc.err}, responder.NewJSONResponder(logger))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/address/search",
+				strings.NewReader(`{"query":"Москва"}`))
+			if tc.cancelCtx {
+				ctx, cancel := context.WithCancel(req.Context())
+				cancel()
+				req = req.WithContext(ctx)
+			}
+			rec := httptest.NewRecorder()
+			ctrl.AddressSearch(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("got %d, want %d (body: %s)", rec.Code, tc.wantStatus, rec.Body.String())
+			}
+			if logs := buf.String(); strings.Contains(logs, "level=ERROR") {
+				t.Errorf("context error must not be logged as server error: %s", logs)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/entity"
+)
+
+// streamConcurrency ограничивает число одновременных запросов геокодирования
+// в потоковой ручке.
+const streamConcurrency = 8
+
+// defaultStreamTimeout — общий дедлайн потоковой ручки, если клиент не задал
+// его заголовком X-Request-Timeout.
+const defaultStreamTimeout = 30 * time.Second
+
+// StreamGeocodeResult — строка NDJSON-ответа потокового геокодирования.
+type StreamGeocodeResult struct {
+	Lat       string            `json:"lat"`
+	Lon       string            `json:"lon"`
+	Addresses []*entity.Address `json:"addresses,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	// TimedOut выставляется для записей, не успевших до общего дедлайна.
+	TimedOut bool `json:"timed_out,omitempty"`
+}
+
+// requestTimeout читает дедлайн из заголовка X-Request-Timeout: либо
+// длительность в формате Go ("5s"), либо число миллисекунд.
+func requestTimeout(r *http.Request, def time.Duration) time.Duration {
+	v := r.Header.Get("X-Request-Timeout")
+	if v == "" {
+		return def
+	}
+	if d, err := time.ParseDuration(v); err == nil && d > 0 {
+		return d
+	}
+	if ms, err := strconv.Atoi(v); err == nil && ms > 0 {
+		return time.Duration(ms) * time.Millisecond
+	}
+	return def
+}
+
+// GeoCodeStream геокодирует поток координат. Вход — NDJSON, по одной паре
+// {lat, lon} на строку; выход — NDJSON, по одному результату на строку,
+// в порядке завершения. Ответ отдаётся по мере готовности результатов.
+//
+// @Summary      Потоковое геокодирование
+// @Tags         address
+// @Accept       json
+// @Produce      json
+// @Param        request body GeocodeRequest true "NDJSON: по одной паре координат на строку"
+// @Success      200 {object} StreamGeocodeResult
+// @Security     BearerAuth
+// @Router       /api/address/geocode/stream [post]
+func (c *GeoController) GeoCodeStream(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, streamConcurrency)
+	)
+	writeResult := func(res StreamGeocodeResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err := enc.Encode(res); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout(r, defaultStreamTimeout))
+	defer cancel()
+
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			break
+		}
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req GeocodeRequest
+		if err := json.Unmarshal(line, &req); err != nil || req.Lat == "" || req.Lon == "" {
+			writeResult(StreamGeocodeResult{Lat: req.Lat, Lon: req.Lon, Error: "invalid input line"})
+			continue
+		}
+
+		// Слот семафора берём с оглядкой на контекст: при отключении
+		// клиента продьюсер не должен висеть в ожидании занятых воркеров.
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			writeResult(StreamGeocodeResult{Lat: req.Lat, Lon: req.Lon, TimedOut: true})
+			continue
+		}
+
+		wg.Add(1)
+		go func(req GeocodeRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if ctx.Err() != nil {
+				writeResult(StreamGeocodeResult{Lat: req.Lat, Lon: req.Lon, TimedOut: true})
+				return
+			}
+
+			// GeoServicer не принимает контекст, поэтому поиск выполняется в
+			// отдельной горутине, а дедлайн контролируется здесь. Зависший
+			// поиск дорабатывает в фоне, но строку ответа не задерживает.
+			done := make(chan StreamGeocodeResult, 1)
+			go func() {
+				addresses, err := c.geo.GeoCode(req.Lat, req.Lon)
+				if err != nil {
+					done <- StreamGeocodeResult{Lat: req.Lat, Lon: req.Lon, Error: "geocode failed"}
+					return
+				}
+				done <- StreamGeocodeResult{Lat: req.Lat, Lon: req.Lon, Addresses: addresses}
+			}()
+
+			select {
+			case res := <-done:
+				writeResult(res)
+			case <-ctx.Done():
+				writeResult(StreamGeocodeResult{Lat: req.Lat, Lon: req.Lon, TimedOut: true})
+			}
+		}(req)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/entity"
+)
+
+func TestGeoCodeStream_LinePerInput(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	body := strings.Join([]string{
+		`{"lat":"55.75","lon":"37.61"}`,
+		`{"lat":"59.93","lon":"30.31"}`,
+		`{"lat":"56.83","lon":"60.60"}`,
+	}, "\n")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/address/geocode/stream", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("got Content-Type %q, want application/x-ndjson", ct)
+	}
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d output lines, want 3: %q", len(lines), lines)
+	}
+
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		var res StreamGeocodeResult
+		if err := json.Unmarshal([]byte(line), &res); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", line, err)
+		}
+		if res.Error != "" {
+			t.Errorf("unexpected error in line %q", line)
+		}
+		if len(res.Addresses) == 0 {
+			t.Errorf("no addresses in line %q", line)
+		}
+		seen[res.Lat] = true
+	}
+	for _, lat := range []string{"55.75", "59.93", "56.83"} {
+		if !seen[lat] {
+			t.Errorf("no output line for lat %s", lat)
+		}
+	}
+}
+
+func TestGeoCodeStream_DeadlineMarksTimedOut(t *testing.T) {
+	geo := &fakeGeo{
+		addresses: []*entity.Address{{City: "Москва", Lat: "55.75", Lon: "37.61"}},
+		slowLat:   "59.93",
+		delay:     time.Second,
+	}
+	router, _ := newTestRouterGeo(t, nil, geo)
+
+	body := `{"lat":"55.75","lon":"37.61"}` + "\n" + `{"lat":"59.93","lon":"30.31"}` + "\n"
+	req := httptest.NewRequest(http.MethodPost, "/api/address/geocode/stream", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+	req.Header.Set("X-Request-Timeout", "100ms")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d output lines, want 2: %q", len(lines), lines)
+	}
+
+	byLat := make(map[string]StreamGeocodeResult)
+	for _, line := range lines {
+		var res StreamGeocodeResult
+		if err := json.Unmarshal([]byte(line), &res); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", line, err)
+		}
+		byLat[res.Lat] = res
+	}
+
+	if res := byLat["55.75"]; res.TimedOut || len(res.Addresses) == 0 {
+		t.Errorf("fast lookup should complete: %+v", res)
+	}
+	if res := byLat["59.93"]; !res.TimedOut {
+		t.Errorf("slow lookup should be marked timed out: %+v", res)
+	}
+}
+
+func TestGeoCodeStream_InvalidLineReported(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	body := "not-json\n" + `{"lat":"55.75","lon":"37.61"}` + "\n"
+	req := httptest.NewRequest(http.MethodPost, "/api/address/geocode/stream", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d output lines, want 2: %q", len(lines), lines)
+	}
+
+	errors := 0
+	for _, line := range lines {
+		var res StreamGeocodeResult
+		if err := json.Unmarshal([]byte(line), &res); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", line, err)
+		}
+		if res.Error != "" {
+			errors++
+		}
+	}
+	if errors != 1 {
+		t.Errorf("got %d error lines, want 1", errors)
+	}
+}
+
+func TestGeoCodeStream_CancelledContextDrainsWorkers(t *testing.T) {
+	geo := &fakeGeo{
+		addresses: []*entity.Address{{City: "Москва"}},
+		slowLat:   "55.75",
+		delay:     200 * time.Millisecond,
+	}
+	router, _ := newTestRouterGeo(t, nil, geo)
+
+	var lines []string
+	for i := 0; i < 20; i++ {
+		lines = append(lines, `{"lat":"55.75","lon":"37.61"}`)
+	}
+	body := strings.Join(lines, "\n")
+
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // клиент отключился до начала обработки
+
+	req := httptest.NewRequest(http.MethodPost, "/api/address/geocode/stream", strings.NewReader(body))
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+	rec := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		router.ServeHTTP(rec, req)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler did not return after context cancellation")
+	}
+
+	// Воркеры должны доработать и завершиться, не оставив горутин.
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before+2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("goroutines leaked: %d before, %d after", before, runtime.NumGoroutine())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
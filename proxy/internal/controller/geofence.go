@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GeoFence — прямоугольная зона допустимых координат геокодирования.
+// Запросы с точками вне зоны отклоняются до обращения к провайдеру —
+// защита от сканирования координат, когда сервис обслуживает одну страну.
+type GeoFence struct {
+	MinLat float64
+	MinLon float64
+	MaxLat float64
+	MaxLon float64
+}
+
+// ParseGeoFence разбирает зону из строки переменной GEO_FENCE в формате
+// "minLat,minLon,maxLat,maxLon". Пустая строка означает, что зона не
+// задана, — возвращается nil без ошибки.
+func ParseGeoFence(s string) (*GeoFence, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("geofence %q: want 4 comma-separated values, got %d", s, len(parts))
+	}
+
+	vals := make([]float64, 4)
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, fmt.Errorf("geofence %q: invalid value %q: %w", s, p, err)
+		}
+		vals[i] = v
+	}
+
+	f := &GeoFence{MinLat: vals[0], MinLon: vals[1], MaxLat: vals[2], MaxLon: vals[3]}
+	switch {
+	case f.MinLat < -90 || f.MaxLat > 90:
+		return nil, fmt.Errorf("geofence %q: latitude outside [-90, 90]", s)
+	case f.MinLon < -180 || f.MaxLon > 180:
+		return nil, fmt.Errorf("geofence %q: longitude outside [-180, 180]", s)
+	case f.MinLat > f.MaxLat || f.MinLon > f.MaxLon:
+		return nil, fmt.Errorf("geofence %q: min corner must not exceed max corner", s)
+	}
+	return f, nil
+}
+
+// Contains сообщает, лежит ли точка внутри зоны (границы включительно).
+func (f *GeoFence) Contains(lat, lon float64) bool {
+	return lat >= f.MinLat && lat <= f.MaxLat && lon >= f.MinLon && lon <= f.MaxLon
+}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/db"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/metrics"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/responder"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/version"
+)
+
+// HealthController — обработчики проверок живости и готовности.
+type HealthController struct {
+	db        *db.SQLAdapter
+	responder *responder.JSONResponder
+}
+
+// NewHealthController создаёт контроллер health-проверок.
+func NewHealthController(adapter *db.SQLAdapter, rsp *responder.JSONResponder) *HealthController {
+	return &HealthController{db: adapter, responder: rsp}
+}
+
+// Health — проверка живости процесса.
+func (c *HealthController) Health(w http.ResponseWriter, r *http.Request) {
+	c.responder.Respond(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Version возвращает сведения о текущей сборке.
+func (c *HealthController) Version(w http.ResponseWriter, r *http.Request) {
+	c.responder.Respond(w, http.StatusOK, version.Get())
+}
+
+// Ready — проверка готовности (доступность базы). Результат дублируется
+// в gauge service_ready, чтобы алёртинг следил за ним через Prometheus,
+// не опрашивая сам эндпоинт.
+func (c *HealthController) Ready(w http.ResponseWriter, r *http.Request) {
+	if c.db == nil {
+		metrics.ServiceReady.Set(1)
+		c.responder.Respond(w, http.StatusOK, map[string]string{"status": "ok"})
+		return
+	}
+	if err := c.db.Ping(r.Context()); err != nil {
+		metrics.ServiceReady.Set(0)
+		c.responder.Error(w, http.StatusServiceUnavailable, "database unavailable")
+		return
+	}
+	metrics.ServiceReady.Set(1)
+	c.responder.Respond(w, http.StatusOK, map[string]string{"status": "ok"})
+}
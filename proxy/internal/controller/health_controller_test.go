@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/db"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/metrics"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/responder"
+)
+
+// newTestHealthController оборачивает sqlmock в SQLAdapter, чтобы управлять
+// результатом пинга базы из теста.
+func newTestHealthController(t *testing.T) (*HealthController, sqlmock.Sqlmock) {
+	t.Helper()
+	mockDB, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { mockDB.Close() })
+
+	adapter := &db.SQLAdapter{DB: sqlx.NewDb(mockDB, "sqlmock")}
+	logger := slog.New(slog.NewTextHandler(httptest.NewRecorder(), nil))
+	return NewHealthController(adapter, responder.NewJSONResponder(logger)), mock
+}
+
+func TestReady_GaugeFollowsDependencyHealth(t *testing.T) {
+	c, mock := newTestHealthController(t)
+
+	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	rec := httptest.NewRecorder()
+	c.Ready(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("got %d, want 503", rec.Code)
+	}
+	if got := testutil.ToFloat64(metrics.ServiceReady); got != 0 {
+		t.Errorf("service_ready = %v, want 0 after failed dependency", got)
+	}
+
+	mock.ExpectPing()
+	rec = httptest.NewRecorder()
+	c.Ready(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200", rec.Code)
+	}
+	if got := testutil.ToFloat64(metrics.ServiceReady); got != 1 {
+		t.Errorf("service_ready = %v, want 1 after healthy dependency", got)
+	}
+}
+
+func TestReady_NoDatabaseCountsAsReady(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(httptest.NewRecorder(), nil))
+	c := NewHealthController(nil, responder.NewJSONResponder(logger))
+
+	rec := httptest.NewRecorder()
+	c.Ready(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200", rec.Code)
+	}
+	if got := testutil.ToFloat64(metrics.ServiceReady); got != 1 {
+		t.Errorf("service_ready = %v, want 1", got)
+	}
+}
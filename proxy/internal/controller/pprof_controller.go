@@ -0,0 +1,432 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"runtime/trace"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/responder"
+)
+
+// CPUProfileOptions — параметры снятия CPU-профиля.
+type CPUProfileOptions struct {
+	// Duration — длительность профилирования в миллисекундах.
+	Duration int    `json:"duration"`
+	FilePath string `json:"file_path"`
+}
+
+// HeapProfileOptions — параметры снятия heap-профиля.
+type HeapProfileOptions struct {
+	FilePath string `json:"file_path"`
+}
+
+// TraceProfileOptions — параметры снятия трассировки.
+type TraceProfileOptions struct {
+	// Duration — длительность трассировки в миллисекундах.
+	Duration int    `json:"duration"`
+	FilePath string `json:"file_path"`
+}
+
+// SnapshotOptions — параметры комбинированного снимка профилей.
+type SnapshotOptions struct {
+	// Duration — длительность CPU-профиля в миллисекундах.
+	Duration int `json:"duration"`
+}
+
+// ProfileSession — активный сеанс профилирования.
+type ProfileSession struct {
+	Type       string    `json:"type"`
+	File       string    `json:"file"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int       `json:"duration_ms"`
+}
+
+// PprofController — обработчики снятия профилей через API.
+type PprofController struct {
+	dir string
+	// retention — срок хранения старых профилей; более старые файлы
+	// удаляются при снятии нового профиля.
+	retention time.Duration
+	// maxDuration — верхняя граница запрошенной длительности профиля.
+	maxDuration time.Duration
+	responder   *responder.JSONResponder
+
+	// mu защищает sessions — реестр активных сеансов по типу профиля.
+	// Рантайм и так не даёт запустить два CPU-профиля, поэтому тип
+	// подходит как ключ.
+	mu       sync.Mutex
+	sessions map[string]ProfileSession
+}
+
+// NewPprofController создаёт контроллер профилирования, пишущий профили в dir.
+func NewPprofController(dir string, retention, maxDuration time.Duration, rsp *responder.JSONResponder) *PprofController {
+	return &PprofController{
+		dir:         dir,
+		retention:   retention,
+		maxDuration: maxDuration,
+		responder:   rsp,
+		sessions:    make(map[string]ProfileSession),
+	}
+}
+
+// trackSession регистрирует запущенный сеанс профилирования.
+func (c *PprofController) trackSession(profileType, file string, durationMS int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sessions[profileType] = ProfileSession{
+		Type:       profileType,
+		File:       file,
+		StartedAt:  time.Now(),
+		DurationMS: durationMS,
+	}
+}
+
+// untrackSession убирает сеанс из реестра после завершения.
+func (c *PprofController) untrackSession(profileType string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.sessions, profileType)
+}
+
+// activeSessions возвращает копию реестра, упорядоченную по времени старта.
+func (c *PprofController) activeSessions() []ProfileSession {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make([]ProfileSession, 0, len(c.sessions))
+	for _, s := range c.sessions {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.Before(out[j].StartedAt) })
+	return out
+}
+
+// ActiveSessions возвращает список активных сеансов профилирования.
+//
+// @Summary      Активные сеансы профилирования
+// @Tags         pprof
+// @Produce      json
+// @Success      200 {object} map[string][]ProfileSession
+// @Security     BearerAuth
+// @Router       /api/pprof/active [get]
+func (c *PprofController) ActiveSessions(w http.ResponseWriter, r *http.Request) {
+	c.responder.Respond(w, http.StatusOK, map[string][]ProfileSession{
+		"sessions": c.activeSessions(),
+	})
+}
+
+// resolveProfilePath приводит пользовательское имя файла к безопасному пути
+// внутри каталога профилей: абсолютные пути и попытки выйти из каталога
+// через «..» отклоняются, из имени берётся только базовая часть.
+func (c *PprofController) resolveProfilePath(name string) (string, error) {
+	if filepath.IsAbs(name) {
+		return "", errors.New("absolute paths are not allowed")
+	}
+	if strings.Contains(name, "..") {
+		return "", errors.New("path traversal is not allowed")
+	}
+	base := filepath.Base(filepath.Clean(name))
+	if base == "." || base == string(filepath.Separator) {
+		return "", errors.New("invalid file name")
+	}
+	return filepath.Join(c.dir, base), nil
+}
+
+// validDuration проверяет, что запрошенная длительность в миллисекундах
+// неотрицательна и не превышает maxDuration.
+func (c *PprofController) validDuration(ms int) bool {
+	if ms < 0 {
+		return false
+	}
+	if c.maxDuration > 0 && time.Duration(ms)*time.Millisecond > c.maxDuration {
+		return false
+	}
+	return true
+}
+
+// cleanupOldProfiles удаляет из dir файлы и каталоги снапшотов старше
+// maxAge. Ошибки намеренно игнорируются: очистка не должна мешать снятию
+// профиля.
+func cleanupOldProfiles(dir string, maxAge time.Duration) {
+	if maxAge <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if e.IsDir() {
+			// Каталоги создаёт только снапшот; прочие не трогаем.
+			if strings.HasPrefix(e.Name(), "snapshot_") {
+				_ = os.RemoveAll(filepath.Join(dir, e.Name()))
+			}
+			continue
+		}
+		_ = os.Remove(filepath.Join(dir, e.Name()))
+	}
+}
+
+// captureCPUProfile синхронно пишет в path CPU-профиль длительностью d.
+func captureCPUProfile(path string, d time.Duration) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := pprof.StartCPUProfile(f); err != nil {
+		return err
+	}
+	time.Sleep(d)
+	pprof.StopCPUProfile()
+	return nil
+}
+
+// writeHeapProfile пишет heap-профиль в path.
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return pprof.WriteHeapProfile(f)
+}
+
+// writeGoroutineDump пишет дамп горутин в path.
+func writeGoroutineDump(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return pprof.Lookup("goroutine").WriteTo(f, 0)
+}
+
+// StartCPUProfile запускает CPU-профиль на заданную длительность.
+//
+// @Summary      Снять CPU-профиль
+// @Tags         pprof
+// @Accept       json
+// @Produce      json
+// @Param        request body CPUProfileOptions true "Параметры профиля"
+// @Success      200 {object} map[string]string
+// @Security     BearerAuth
+// @Router       /api/pprof/cpu [post]
+func (c *PprofController) StartCPUProfile(w http.ResponseWriter, r *http.Request) {
+	var opts CPUProfileOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		c.responder.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if !c.validDuration(opts.Duration) {
+		c.responder.Error(w, http.StatusBadRequest, "profile duration out of range")
+		return
+	}
+	if opts.Duration == 0 {
+		opts.Duration = 5000
+	}
+	if opts.FilePath == "" {
+		opts.FilePath = filepath.Join(c.dir, time.Now().Format("cpu_20060102_150405.pprof"))
+	} else {
+		path, err := c.resolveProfilePath(opts.FilePath)
+		if err != nil {
+			c.responder.Error(w, http.StatusBadRequest, "invalid file path")
+			return
+		}
+		opts.FilePath = path
+	}
+
+	cleanupOldProfiles(c.dir, c.retention)
+
+	f, err := os.Create(opts.FilePath)
+	if err != nil {
+		c.responder.Error(w, http.StatusInternalServerError, "create profile file")
+		return
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		c.responder.Error(w, http.StatusConflict, "cpu profile already running")
+		return
+	}
+
+	c.trackSession("cpu", opts.FilePath, opts.Duration)
+	go func() {
+		time.Sleep(time.Duration(opts.Duration) * time.Millisecond)
+		pprof.StopCPUProfile()
+		f.Close()
+		c.untrackSession("cpu")
+	}()
+
+	c.responder.Respond(w, http.StatusOK, map[string]string{"file": opts.FilePath})
+}
+
+// TakeHeapProfile снимает heap-профиль.
+//
+// @Summary      Снять heap-профиль
+// @Tags         pprof
+// @Accept       json
+// @Produce      json
+// @Param        request body HeapProfileOptions true "Параметры профиля"
+// @Success      200 {object} map[string]string
+// @Security     BearerAuth
+// @Router       /api/pprof/heap [post]
+func (c *PprofController) TakeHeapProfile(w http.ResponseWriter, r *http.Request) {
+	var opts HeapProfileOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		c.responder.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if opts.FilePath == "" {
+		opts.FilePath = filepath.Join(c.dir, time.Now().Format("heap_20060102_150405.pprof"))
+	} else {
+		path, err := c.resolveProfilePath(opts.FilePath)
+		if err != nil {
+			c.responder.Error(w, http.StatusBadRequest, "invalid file path")
+			return
+		}
+		opts.FilePath = path
+	}
+
+	cleanupOldProfiles(c.dir, c.retention)
+
+	if err := writeHeapProfile(opts.FilePath); err != nil {
+		c.responder.Error(w, http.StatusInternalServerError, "write heap profile")
+		return
+	}
+
+	c.responder.Respond(w, http.StatusOK, map[string]string{"file": opts.FilePath})
+}
+
+// Snapshot снимает комбинированный срез: heap-профиль, дамп горутин и
+// короткий CPU-профиль — в отдельный подкаталог с отметкой времени.
+//
+// @Summary      Комбинированный снимок профилей
+// @Tags         pprof
+// @Accept       json
+// @Produce      json
+// @Param        request body SnapshotOptions true "Параметры снимка"
+// @Success      200 {object} map[string][]string
+// @Security     BearerAuth
+// @Router       /api/pprof/snapshot [post]
+func (c *PprofController) Snapshot(w http.ResponseWriter, r *http.Request) {
+	var opts SnapshotOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		c.responder.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if !c.validDuration(opts.Duration) {
+		c.responder.Error(w, http.StatusBadRequest, "profile duration out of range")
+		return
+	}
+	if opts.Duration == 0 {
+		opts.Duration = 1000
+	}
+
+	cleanupOldProfiles(c.dir, c.retention)
+
+	dir := filepath.Join(c.dir, time.Now().Format("snapshot_20060102_150405"))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		c.responder.Error(w, http.StatusInternalServerError, "create snapshot directory")
+		return
+	}
+
+	heapFile := filepath.Join(dir, "heap.pprof")
+	if err := writeHeapProfile(heapFile); err != nil {
+		c.responder.Error(w, http.StatusInternalServerError, "write heap profile")
+		return
+	}
+
+	goroutineFile := filepath.Join(dir, "goroutine.txt")
+	if err := writeGoroutineDump(goroutineFile); err != nil {
+		c.responder.Error(w, http.StatusInternalServerError, "write goroutine dump")
+		return
+	}
+
+	cpuFile := filepath.Join(dir, "cpu.pprof")
+	c.trackSession("cpu", cpuFile, opts.Duration)
+	err := captureCPUProfile(cpuFile, time.Duration(opts.Duration)*time.Millisecond)
+	c.untrackSession("cpu")
+	if err != nil {
+		c.responder.Error(w, http.StatusConflict, "cpu profile already running")
+		return
+	}
+
+	c.responder.Respond(w, http.StatusOK, map[string][]string{
+		"files": {heapFile, goroutineFile, cpuFile},
+	})
+}
+
+// StartTraceProfile запускает трассировку на заданную длительность.
+//
+// @Summary      Снять трассировку
+// @Tags         pprof
+// @Accept       json
+// @Produce      json
+// @Param        request body TraceProfileOptions true "Параметры трассировки"
+// @Success      200 {object} map[string]string
+// @Security     BearerAuth
+// @Router       /api/pprof/trace [post]
+func (c *PprofController) StartTraceProfile(w http.ResponseWriter, r *http.Request) {
+	var opts TraceProfileOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		c.responder.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if !c.validDuration(opts.Duration) {
+		c.responder.Error(w, http.StatusBadRequest, "trace duration out of range")
+		return
+	}
+	if opts.Duration == 0 {
+		opts.Duration = 1000
+	}
+	if opts.FilePath == "" {
+		opts.FilePath = filepath.Join(c.dir, time.Now().Format("trace_20060102_150405.out"))
+	} else {
+		path, err := c.resolveProfilePath(opts.FilePath)
+		if err != nil {
+			c.responder.Error(w, http.StatusBadRequest, "invalid file path")
+			return
+		}
+		opts.FilePath = path
+	}
+
+	cleanupOldProfiles(c.dir, c.retention)
+
+	f, err := os.Create(opts.FilePath)
+	if err != nil {
+		c.responder.Error(w, http.StatusInternalServerError, "create trace file")
+		return
+	}
+	if err := trace.Start(f); err != nil {
+		f.Close()
+		c.responder.Error(w, http.StatusConflict, "trace already running")
+		return
+	}
+
+	c.trackSession("trace", opts.FilePath, opts.Duration)
+	go func() {
+		time.Sleep(time.Duration(opts.Duration) * time.Millisecond)
+		trace.Stop()
+		f.Close()
+		c.untrackSession("trace")
+	}()
+
+	c.responder.Respond(w, http.StatusOK, map[string]string{"file": opts.FilePath})
+}
@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/responder"
+)
+
+func newTestPprofController(t *testing.T) (*PprofController, string) {
+	t.Helper()
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(httptest.NewRecorder(), nil))
+	return NewPprofController(dir, 7*24*time.Hour, time.Minute, responder.NewJSONResponder(logger)), dir
+}
+
+func TestStartCPUProfile_DurationBounds(t *testing.T) {
+	c, _ := newTestPprofController(t)
+
+	for _, tc := range []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{"over max", `{"duration":120000}`, http.StatusBadRequest},
+		{"negative", `{"duration":-5}`, http.StatusBadRequest},
+		{"valid", `{"duration":50}`, http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/pprof/cpu",
+				strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+			c.StartCPUProfile(rec, req)
+
+			if rec.Code != tc.wantCode {
+				t.Errorf("got %d, want %d (body: %s)", rec.Code, tc.wantCode, rec.Body.String())
+			}
+		})
+	}
+	// Дожидаемся остановки фонового CPU-профиля из валидного кейса,
+	// чтобы он не конфликтовал с другими тестами.
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestTakeHeapProfile_PathTraversalRejected(t *testing.T) {
+	c, _ := newTestPprofController(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/pprof/heap",
+		strings.NewReader(`{"file_path":"../../etc/evil.pprof"}`))
+	rec := httptest.NewRecorder()
+	c.TakeHeapProfile(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("got %d, want 400 (body: %s)", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTakeHeapProfile_RelativeNameStaysInDir(t *testing.T) {
+	c, dir := newTestPprofController(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/pprof/heap",
+		strings.NewReader(`{"file_path":"debug.pprof"}`))
+	rec := httptest.NewRecorder()
+	c.TakeHeapProfile(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "debug.pprof")); err != nil {
+		t.Errorf("profile must be written inside pprof dir: %v", err)
+	}
+}
+
+func TestStartTraceProfile_DurationOverMax(t *testing.T) {
+	c, _ := newTestPprofController(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/pprof/trace",
+		strings.NewReader(`{"duration":3600000}`))
+	rec := httptest.NewRecorder()
+	c.StartTraceProfile(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("got %d, want 400 (body: %s)", rec.Code, rec.Body.String())
+	}
+}
+
+func TestSnapshot_CreatesAllFiles(t *testing.T) {
+	c, dir := newTestPprofController(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/pprof/snapshot",
+		strings.NewReader(`{"duration":50}`))
+	rec := httptest.NewRecorder()
+	c.Snapshot(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+
+	var resp map[string][]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	files := resp["files"]
+	if len(files) != 3 {
+		t.Fatalf("got %d files, want 3: %v", len(files), files)
+	}
+	for _, f := range files {
+		if !strings.HasPrefix(f, dir) {
+			t.Errorf("file %q outside pprof dir %q", f, dir)
+		}
+		info, err := os.Stat(f)
+		if err != nil {
+			t.Errorf("stat %s: %v", f, err)
+			continue
+		}
+		if info.Size() == 0 {
+			t.Errorf("file %s is empty", f)
+		}
+	}
+}
+
+func TestCleanupOldProfiles(t *testing.T) {
+	dir := t.TempDir()
+
+	oldFile := filepath.Join(dir, "cpu_20200101_000000.pprof")
+	freshFile := filepath.Join(dir, "heap_20260101_000000.pprof")
+	for _, name := range []string{oldFile, freshFile} {
+		if err := os.WriteFile(name, []byte("profile"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	stale := time.Now().Add(-30 * 24 * time.Hour)
+	if err := os.Chtimes(oldFile, stale, stale); err != nil {
+		t.Fatalf("backdate %s: %v", oldFile, err)
+	}
+
+	subDir := filepath.Join(dir, "nested")
+	oldSnapshot := filepath.Join(dir, "snapshot_20200101_000000")
+	freshSnapshot := filepath.Join(dir, "snapshot_20260101_000000")
+	for _, d := range []string{subDir, oldSnapshot, freshSnapshot} {
+		if err := os.Mkdir(d, 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", d, err)
+		}
+		if err := os.WriteFile(filepath.Join(d, "heap.pprof"), []byte("profile"), 0o644); err != nil {
+			t.Fatalf("write snapshot file: %v", err)
+		}
+	}
+	for _, d := range []string{subDir, oldSnapshot} {
+		if err := os.Chtimes(d, stale, stale); err != nil {
+			t.Fatalf("backdate %s: %v", d, err)
+		}
+	}
+
+	cleanupOldProfiles(dir, 7*24*time.Hour)
+
+	if _, err := os.Stat(oldFile); !os.IsNotExist(err) {
+		t.Errorf("old profile must be removed, stat err: %v", err)
+	}
+	if _, err := os.Stat(freshFile); err != nil {
+		t.Errorf("fresh profile must survive: %v", err)
+	}
+	if _, err := os.Stat(oldSnapshot); !os.IsNotExist(err) {
+		t.Errorf("expired snapshot directory must be removed, stat err: %v", err)
+	}
+	if _, err := os.Stat(freshSnapshot); err != nil {
+		t.Errorf("fresh snapshot directory must survive: %v", err)
+	}
+	if _, err := os.Stat(subDir); err != nil {
+		t.Errorf("non-snapshot subdirectories must not be touched: %v", err)
+	}
+}
+
+func TestCleanupOldProfiles_ZeroRetentionKeepsAll(t *testing.T) {
+	dir := t.TempDir()
+
+	name := filepath.Join(dir, "trace_20200101_000000.out")
+	if err := os.WriteFile(name, []byte("trace"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	stale := time.Now().Add(-365 * 24 * time.Hour)
+	if err := os.Chtimes(name, stale, stale); err != nil {
+		t.Fatalf("backdate: %v", err)
+	}
+
+	cleanupOldProfiles(dir, 0)
+
+	if _, err := os.Stat(name); err != nil {
+		t.Errorf("zero retention must disable cleanup: %v", err)
+	}
+}
+
+func TestActiveSessions_TracksCPUProfile(t *testing.T) {
+	c, _ := newTestPprofController(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/pprof/cpu",
+		strings.NewReader(`{"duration":200}`))
+	rec := httptest.NewRecorder()
+	c.StartCPUProfile(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("start cpu profile: got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+
+	sessions := c.activeSessions()
+	if len(sessions) != 1 || sessions[0].Type != "cpu" {
+		t.Fatalf("got sessions %+v, want one cpu session", sessions)
+	}
+	if sessions[0].File == "" || sessions[0].DurationMS != 200 {
+		t.Errorf("session missing details: %+v", sessions[0])
+	}
+
+	// После завершения профиля сеанс должен исчезнуть из реестра.
+	deadline := time.Now().Add(2 * time.Second)
+	for len(c.activeSessions()) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("session still active after profile completion")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestActiveSessions_EmptyByDefault(t *testing.T) {
+	c, _ := newTestPprofController(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/pprof/active", nil)
+	rec := httptest.NewRecorder()
+	c.ActiveSessions(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200", rec.Code)
+	}
+	var resp struct {
+		Sessions []ProfileSession `json:"sessions"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Sessions) != 0 {
+		t.Errorf("got %d sessions, want 0", len(resp.Sessions))
+	}
+}
@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+
+	_ "github.com/sizotov81-hub/Geoservis/proxy/docs"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/config"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/metrics"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/middleware"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/responder"
+)
+
+// RouterDeps — зависимости, необходимые для сборки маршрутизатора.
+type RouterDeps struct {
+	Cfg         *config.Config
+	Logger      *slog.Logger
+	Auth        *AuthController
+	Users       *UserController
+	Geo         *GeoController
+	Pprof       *PprofController
+	Health      *HealthController
+	Admin       *AdminController
+	Maintenance *middleware.Maintenance
+}
+
+// quickReadTimeout — бюджет времени для быстрых точечных чтений: им не
+// нужен весь глобальный REQUEST_TIMEOUT, рассчитанный на пакетные маршруты.
+const quickReadTimeout = 5 * time.Second
+
+// NewRouter собирает все маршруты сервиса.
+func NewRouter(d RouterDeps) *chi.Mux {
+	r := chi.NewRouter()
+
+	// Стандартные ответы chi на 404/405 — plain text; приводим их к общему
+	// JSON-формату ошибок сервиса.
+	rsp := responder.NewJSONResponder(d.Logger).WithPretty(d.Cfg.PrettyJSON)
+	r.NotFound(func(w http.ResponseWriter, _ *http.Request) {
+		rsp.Error(w, http.StatusNotFound, "not found")
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, _ *http.Request) {
+		rsp.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+	})
+
+	// Нормализация пути до маршрутизации и метрик: `/api//users` и
+	// `/api/users/` ведут на тот же обработчик и в ту же метку path.
+	r.Use(chimiddleware.CleanPath)
+	r.Use(chimiddleware.StripSlashes)
+	r.Use(chimiddleware.RequestID)
+	r.Use(chimiddleware.RealIP)
+	r.Use(middleware.SecurityHeaders(d.Cfg.HSTSEnabled))
+	r.Use(middleware.ConcurrencyLimit(d.Cfg.MaxConcurrentRequests))
+	r.Use(middleware.RequestTimeout(d.Cfg.RequestTimeout))
+	r.Use(middleware.HeadToGet)
+	r.Use(middleware.Recover(d.Logger))
+	r.Use(middleware.HTTPMetricsMiddleware)
+	r.Use(middleware.SlowRequestLog(d.Logger, d.Cfg.HTTPSlowThreshold))
+
+	r.Get("/health", d.Health.Health)
+	r.Get("/readyz", d.Health.Ready)
+	r.Get("/version", d.Health.Version)
+	r.Handle("/metrics", metrics.Handler())
+
+	swaggerUI := swaggerUIHandler(d.Cfg.PublicBaseURL)
+	if d.Cfg.SwaggerUser != "" && d.Cfg.SwaggerPassword != "" {
+		r.Group(func(docs chi.Router) {
+			docs.Use(chimiddleware.BasicAuth("swagger", map[string]string{
+				d.Cfg.SwaggerUser: d.Cfg.SwaggerPassword,
+			}))
+			docs.Get("/swagger/*", swaggerUI)
+		})
+	} else {
+		r.Get("/swagger/*", swaggerUI)
+	}
+	r.Get("/openapi.json", openAPIHandler)
+
+	r.Route("/api", func(api chi.Router) {
+		api.Use(d.Maintenance.Middleware)
+		if d.Cfg.StrictAccept {
+			api.Use(middleware.StrictAccept)
+		}
+
+		api.Group(func(public chi.Router) {
+			public.Post("/register", d.Auth.RegisterHandler)
+			public.Post("/login", d.Auth.LoginHandler)
+		})
+
+		api.Group(func(private chi.Router) {
+			private.Use(middleware.AuthMiddleware(d.Cfg.JWTSecret, d.Cfg.JWTOldSecrets, d.Cfg.JWTIssuer, d.Cfg.JWTAudience))
+
+			private.Route("/address", func(address chi.Router) {
+				address.Post("/search", d.Geo.AddressSearch)
+				address.Post("/search/batch", d.Geo.AddressSearchBatch)
+				address.Post("/geocode", d.Geo.GeoCode)
+				address.Post("/geocode/stream", d.Geo.GeoCodeStream)
+				address.Post("/validate", d.Geo.ValidateAddress)
+			})
+
+			private.Route("/users", func(users chi.Router) {
+				users.Get("/", d.Users.ListUsers)
+				users.Get("/export.csv", d.Users.ExportCSV)
+				users.Get("/export.json", d.Users.ExportJSON)
+				users.Post("/bulk-delete", d.Users.BulkDeleteUsers)
+				users.Post("/me/location", d.Users.SetLocation)
+				users.Post("/me/email", d.Users.RequestEmailChange)
+				users.Post("/me/email/verify", d.Users.VerifyEmailChange)
+				users.Get("/{id}", middleware.RouteTimeout(quickReadTimeout, d.Users.GetUser))
+				users.Put("/{id}", d.Users.UpdateUser)
+				users.Patch("/{id}", d.Users.PatchUser)
+				users.Delete("/{id}", d.Users.DeleteUser)
+			})
+
+			private.Route("/admin", func(admin chi.Router) {
+				admin.Post("/maintenance", d.Admin.SetMaintenance)
+				admin.Post("/gc", d.Admin.RunGC)
+				admin.Post("/cache/flush", d.Admin.FlushCache)
+				admin.Get("/cache/entry", d.Admin.CacheEntry)
+				admin.Post("/impersonate", d.Admin.Impersonate)
+				admin.Get("/config", d.Admin.ShowConfig)
+			})
+
+			private.Route("/pprof", func(pp chi.Router) {
+				pp.Post("/cpu", d.Pprof.StartCPUProfile)
+				pp.Post("/heap", d.Pprof.TakeHeapProfile)
+				pp.Post("/trace", d.Pprof.StartTraceProfile)
+				pp.Post("/snapshot", d.Pprof.Snapshot)
+				pp.Get("/active", d.Pprof.ActiveSessions)
+			})
+		})
+	})
+
+	return r
+}
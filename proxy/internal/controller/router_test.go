@@ -0,0 +1,403 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/cache"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/config"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/entity"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/middleware"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/repository"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/responder"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/service"
+)
+
+// fakeUserRepo — репозиторий в памяти для тестов контроллеров.
+type fakeUserRepo struct {
+	users  map[int]*entity.User
+	nextID int
+}
+
+func newFakeUserRepo() *fakeUserRepo {
+	return &fakeUserRepo{users: make(map[int]*entity.User), nextID: 1}
+}
+
+func (f *fakeUserRepo) Create(_ context.Context, user *entity.User) error {
+	for _, u := range f.users {
+		if u.Email == user.Email && u.DeletedAt == nil {
+			return repository.ErrAlreadyExists
+		}
+	}
+	user.ID = f.nextID
+	f.nextID++
+	now := time.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	f.users[user.ID] = user
+	return nil
+}
+
+func (f *fakeUserRepo) GetByID(_ context.Context, id int) (*entity.User, error) {
+	u, ok := f.users[id]
+	if !ok || u.DeletedAt != nil {
+		return nil, repository.ErrNotFound
+	}
+	return u, nil
+}
+
+func (f *fakeUserRepo) GetByEmail(_ context.Context, email string) (*entity.User, error) {
+	for _, u := range f.users {
+		if u.Email == email && u.DeletedAt == nil {
+			return u, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (f *fakeUserRepo) Update(_ context.Context, user *entity.User) error {
+	// Как и настоящий репозиторий, заглушка проверяет инварианты сущности.
+	if err := user.Validate(); err != nil {
+		return err
+	}
+	u, ok := f.users[user.ID]
+	if !ok || u.DeletedAt != nil {
+		return repository.ErrNotFound
+	}
+	user.CreatedAt = u.CreatedAt
+	user.UpdatedAt = time.Now()
+	f.users[user.ID] = user
+	return nil
+}
+
+func (f *fakeUserRepo) Delete(_ context.Context, id int, reason string) error {
+	u, ok := f.users[id]
+	if !ok || u.DeletedAt != nil {
+		return repository.ErrNotFound
+	}
+	now := time.Now()
+	u.DeletedAt = &now
+	if reason != "" {
+		u.DeletedReason = &reason
+	}
+	return nil
+}
+
+func (f *fakeUserRepo) DeleteBatch(_ context.Context, ids []int) ([]int, error) {
+	deleted := make([]int, 0, len(ids))
+	for _, id := range ids {
+		u, ok := f.users[id]
+		if !ok || u.DeletedAt != nil {
+			continue
+		}
+		now := time.Now()
+		u.DeletedAt = &now
+		deleted = append(deleted, id)
+	}
+	return deleted, nil
+}
+
+func (f *fakeUserRepo) SetLocation(_ context.Context, id int, city, street string) error {
+	u, ok := f.users[id]
+	if !ok || u.DeletedAt != nil {
+		return repository.ErrNotFound
+	}
+	u.LocationCity, u.LocationStreet = nil, nil
+	if city != "" {
+		u.LocationCity = &city
+	}
+	if street != "" {
+		u.LocationStreet = &street
+	}
+	return nil
+}
+
+func (f *fakeUserRepo) ListAll(_ context.Context, limit, offset int) ([]*entity.User, error) {
+	out := make([]*entity.User, 0)
+	for id := 1; id < f.nextID; id++ {
+		if u, ok := f.users[id]; ok {
+			out = append(out, u)
+		}
+	}
+	if offset >= len(out) {
+		return []*entity.User{}, nil
+	}
+	out = out[offset:]
+	if limit < len(out) {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (f *fakeUserRepo) ListCreatedBetween(_ context.Context, from, to time.Time, limit, offset int) ([]*entity.User, error) {
+	out := make([]*entity.User, 0)
+	for id := 1; id < f.nextID; id++ {
+		u, ok := f.users[id]
+		if !ok || u.DeletedAt != nil || u.CreatedAt.Before(from) || !u.CreatedAt.Before(to) {
+			continue
+		}
+		out = append(out, u)
+	}
+	if offset >= len(out) {
+		return []*entity.User{}, nil
+	}
+	out = out[offset:]
+	if limit < len(out) {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (f *fakeUserRepo) ListAfter(_ context.Context, afterID, limit int) ([]*entity.User, error) {
+	out := make([]*entity.User, 0)
+	for id := afterID + 1; id < f.nextID && len(out) < limit; id++ {
+		u, ok := f.users[id]
+		if !ok || u.DeletedAt != nil {
+			continue
+		}
+		out = append(out, u)
+	}
+	return out, nil
+}
+
+func (f *fakeUserRepo) List(_ context.Context, limit, offset int) ([]*entity.User, error) {
+	out := make([]*entity.User, 0)
+	for id := 1; id < f.nextID; id++ {
+		u, ok := f.users[id]
+		if !ok || u.DeletedAt != nil {
+			continue
+		}
+		out = append(out, u)
+	}
+	if offset >= len(out) {
+		return []*entity.User{}, nil
+	}
+	out = out[offset:]
+	if limit < len(out) {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (f *fakeUserRepo) SetPendingEmail(_ context.Context, id int, email, token string) error {
+	u, ok := f.users[id]
+	if !ok || u.DeletedAt != nil {
+		return repository.ErrNotFound
+	}
+	u.PendingEmail = &email
+	u.PendingEmailToken = &token
+	return nil
+}
+
+func (f *fakeUserRepo) CommitPendingEmail(_ context.Context, id int, token string) error {
+	u, ok := f.users[id]
+	if !ok || u.DeletedAt != nil || u.PendingEmail == nil ||
+		u.PendingEmailToken == nil || *u.PendingEmailToken != token {
+		return repository.ErrNotFound
+	}
+	for _, other := range f.users {
+		if other.ID != id && other.Email == *u.PendingEmail && other.DeletedAt == nil {
+			return repository.ErrAlreadyExists
+		}
+	}
+	u.Email = *u.PendingEmail
+	u.PendingEmail = nil
+	u.PendingEmailToken = nil
+	return nil
+}
+
+// WithTx в заглушке не даёт транзакционных гарантий: fn выполняется
+// против того же хранилища.
+func (f *fakeUserRepo) WithTx(_ context.Context, fn func(repository.UserRepository) error) error {
+	return fn(f)
+}
+
+// fakeGeo — заглушка GeoServicer. При совпадении lat со slowLat ответ
+// задерживается на delay — для тестов дедлайнов; поиск по строке failQuery
+// завершается ошибкой — для тестов частичных сбоев.
+type fakeGeo struct {
+	addresses []*entity.Address
+	err       error
+	slowLat   string
+	delay     time.Duration
+	failQuery string
+}
+
+func (f *fakeGeo) AddressSearch(input string) ([]*entity.Address, error) {
+	if f.failQuery != "" && input == f.failQuery {
+		return nil, errors.New("upstream failure")
+	}
+	return f.addresses, f.err
+}
+
+func (f *fakeGeo) GeoCode(lat, _ string) ([]*entity.Address, error) {
+	if f.slowLat != "" && lat == f.slowLat {
+		time.Sleep(f.delay)
+	}
+	return f.addresses, f.err
+}
+
+func (f *fakeGeo) AddressSearchWithSource(input string) ([]*entity.Address, string, error) {
+	addresses, err := f.AddressSearch(input)
+	return addresses, service.GeoSourceDadata, err
+}
+
+func (f *fakeGeo) GeoCodeWithSource(lat, lon string) ([]*entity.Address, string, error) {
+	addresses, err := f.GeoCode(lat, lon)
+	return addresses, service.GeoSourceDadata, err
+}
+
+func testConfig() *config.Config {
+	cfg := config.Load()
+	cfg.JWTSecret = "test-secret"
+	cfg.AdminEmails = []string{"admin@example.com"}
+	return cfg
+}
+
+func newTestRouter(t *testing.T) (*chi.Mux, *fakeUserRepo) {
+	return newTestRouterWith(t, nil)
+}
+
+func newTestRouterWith(t *testing.T, mutate func(*config.Config)) (*chi.Mux, *fakeUserRepo) {
+	geo := &fakeGeo{addresses: []*entity.Address{{City: "Москва", Street: "Тверская", Lat: "55.75", Lon: "37.61"}}}
+	return newTestRouterGeo(t, mutate, geo)
+}
+
+func newTestRouterGeo(t *testing.T, mutate func(*config.Config), geo service.GeoServicer) (*chi.Mux, *fakeUserRepo) {
+	t.Helper()
+
+	cfg := testConfig()
+	if mutate != nil {
+		mutate(cfg)
+	}
+	logger := slog.New(slog.NewTextHandler(httptest.NewRecorder(), nil))
+	rsp := responder.NewJSONResponder(logger)
+
+	repo := newFakeUserRepo()
+	userService := service.NewUserService(repo, nil, logger)
+
+	maintenance := middleware.NewMaintenance(cfg.MaintenanceMode)
+	geoCache := cache.NewInMemoryCache(time.Hour)
+
+	router := NewRouter(RouterDeps{
+		Cfg:         cfg,
+		Logger:      logger,
+		Auth:        NewAuthController(userService, cfg, rsp),
+		Users:       NewUserController(userService, geo, rsp, cfg),
+		Geo:         NewGeoController(geo, rsp),
+		Pprof:       NewPprofController(t.TempDir(), 7*24*time.Hour, time.Minute, rsp),
+		Health:      NewHealthController(nil, rsp),
+		Admin:       NewAdminController(maintenance, geoCache, userService, cfg, logger, rsp),
+		Maintenance: maintenance,
+	})
+	return router, repo
+}
+
+func testToken(t *testing.T, secret string) string {
+	return testTokenFor(t, secret, "test@example.com")
+}
+
+func testTokenFor(t *testing.T, secret, email string) string {
+	t.Helper()
+
+	claims := jwt.MapClaims{
+		"email": email,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func TestRouter_Health(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("got %d, want 200", rec.Code)
+	}
+}
+
+func TestRouter_UsersWithoutToken(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("got %d, want 401 when no credentials are presented", rec.Code)
+	}
+}
+
+func TestRouter_UsersWithToken(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("got %d, want 200", rec.Code)
+	}
+}
+
+func TestRouter_UnknownRoute(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/no/such/route", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("got %d, want 404", rec.Code)
+	}
+	if body := strings.TrimSpace(rec.Body.String()); body != `{"error":"not found"}` {
+		t.Errorf("got body %q, want JSON error", body)
+	}
+}
+
+func TestRouter_MethodNotAllowed(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodDelete, "/health", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("got %d, want 405", rec.Code)
+	}
+	if body := strings.TrimSpace(rec.Body.String()); body != `{"error":"method not allowed"}` {
+		t.Errorf("got body %q, want JSON error", body)
+	}
+}
+
+func TestRouter_PathNormalization(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	for _, path := range []string{"/api/users", "/api//users", "/api/users/"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("path %q: got %d, want 200", path, rec.Code)
+		}
+	}
+}
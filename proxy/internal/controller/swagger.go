@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	httpSwagger "github.com/swaggo/http-swagger"
+	"github.com/swaggo/swag"
+)
+
+// swaggerUIHandler отдаёт Swagger UI, вычисляя адрес doc.json по внешнему
+// базовому URL из конфигурации либо по Host входящего запроса, чтобы
+// документация работала не только на localhost.
+func swaggerUIHandler(publicBaseURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		base := strings.TrimSuffix(publicBaseURL, "/")
+		if base == "" {
+			scheme := "http"
+			if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+				scheme = "https"
+			}
+			base = scheme + "://" + r.Host
+		}
+		httpSwagger.Handler(httpSwagger.URL(base+"/swagger/doc.json")).ServeHTTP(w, r)
+	}
+}
+
+// openAPIHandler отдаёт сырой OpenAPI-документ из встроенного пакета docs —
+// стабильный путь для клиентов, генерирующих SDK, независимый от Swagger UI.
+func openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	doc, err := swag.ReadDoc()
+	if err != nil {
+		http.Error(w, `{"error":"openapi document unavailable"}`, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(doc))
+}
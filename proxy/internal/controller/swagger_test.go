@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/config"
+)
+
+func TestSwaggerUI_URLFromRequestHost(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil)
+	req.Host = "api.staging.example.com"
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200", rec.Code)
+	}
+	// UI подставляет адрес в JSON-экранированном виде.
+	if !strings.Contains(rec.Body.String(), `http:\/\/api.staging.example.com\/swagger\/doc.json`) {
+		t.Error("swagger UI must reference doc.json on the request host")
+	}
+	if strings.Contains(rec.Body.String(), "localhost:8080") {
+		t.Error("swagger UI must not hardcode localhost")
+	}
+}
+
+func TestSwaggerUI_BasicAuth(t *testing.T) {
+	router, _ := newTestRouterWith(t, func(cfg *config.Config) {
+		cfg.SwaggerUser = "docs"
+		cfg.SwaggerPassword = "secret"
+	})
+
+	t.Run("without credentials", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("got %d, want 401", rec.Code)
+		}
+	})
+
+	t.Run("with credentials", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil)
+		req.SetBasicAuth("docs", "secret")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got %d, want 200", rec.Code)
+		}
+	})
+}
+
+func TestSwaggerUI_OpenWhenAuthUnset(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("got %d, want 200 when basic auth is not configured", rec.Code)
+	}
+}
+
+func TestOpenAPIJSON(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got Content-Type %q, want application/json", ct)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("body is not valid JSON: %v", err)
+	}
+	if _, ok := doc["paths"]; !ok {
+		t.Error("openapi document must contain the paths key")
+	}
+}
+
+func TestSwaggerUI_URLFromPublicBaseURL(t *testing.T) {
+	router, _ := newTestRouterWith(t, func(cfg *config.Config) {
+		cfg.PublicBaseURL = "https://geo.example.com"
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `https:\/\/geo.example.com\/swagger\/doc.json`) {
+		t.Error("swagger UI must reference doc.json on the configured base URL")
+	}
+}
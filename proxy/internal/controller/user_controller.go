@@ -0,0 +1,646 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/config"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/entity"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/middleware"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/repository"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/responder"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/service"
+	"github.com/sizotov81-hub/Geoservis/proxy/pkg/httputil"
+)
+
+const (
+	defaultLimit = 20
+)
+
+// UserController — обработчики CRUD по пользователям.
+type UserController struct {
+	users     *service.UserService
+	geo       service.GeoServicer
+	responder *responder.JSONResponder
+	cfg       *config.Config
+}
+
+// NewUserController создаёт контроллер пользователей. geo используется для
+// обратного геокодирования в ручке установки местоположения.
+func NewUserController(users *service.UserService, geo service.GeoServicer, rsp *responder.JSONResponder, cfg *config.Config) *UserController {
+	return &UserController{users: users, geo: geo, responder: rsp, cfg: cfg}
+}
+
+// CursorPage — страница пользователей при курсорной пагинации.
+type CursorPage struct {
+	Users []*entity.User `json:"users"`
+	// NextCursor — значение cursor для следующей страницы; 0, если данных больше нет.
+	NextCursor int `json:"next_cursor"`
+}
+
+// ListUsers возвращает страницу пользователей. При передаче параметра cursor
+// используется курсорная пагинация (id > cursor), иначе — limit/offset.
+//
+// @Summary      Список пользователей
+// @Tags         users
+// @Produce      json
+// @Param        limit  query int false "Размер страницы (не больше MAX_PAGE_SIZE, по умолчанию 100; значения сверх максимума урезаются)"
+// @Param        offset query int false "Смещение"
+// @Param        cursor query int false "Курсор: вернуть пользователей с id больше указанного"
+// @Param        include_deleted query bool false "Включить удалённых пользователей (только для администраторов)"
+// @Param        created_from query string false "Начало интервала created_at (RFC3339 или дата), включительно"
+// @Param        created_to query string false "Конец интервала created_at (RFC3339 или дата), не включительно"
+// @Success      200 {array} entity.User
+// @Failure      400 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/users [get]
+func (c *UserController) ListUsers(w http.ResponseWriter, r *http.Request) {
+	limit, offset := httputil.ParsePagination(r, defaultLimit, c.cfg.MaxPageSize)
+
+	if r.URL.Query().Get("include_deleted") == "true" {
+		email, _ := middleware.EmailFromContext(r.Context())
+		if !c.cfg.IsAdmin(email) {
+			c.responder.Error(w, http.StatusForbidden, "admin access required")
+			return
+		}
+
+		users, err := c.users.ListAllUsers(r.Context(), limit, offset)
+		if err != nil {
+			c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		c.responder.Respond(w, http.StatusOK, users)
+		return
+	}
+
+	if r.URL.Query().Has("created_from") || r.URL.Query().Has("created_to") {
+		from := time.Time{}
+		to := time.Now()
+		if v := r.URL.Query().Get("created_from"); v != "" {
+			t, err := parseDateParam(v)
+			if err != nil {
+				c.responder.Error(w, http.StatusBadRequest, "invalid created_from")
+				return
+			}
+			from = t
+		}
+		if v := r.URL.Query().Get("created_to"); v != "" {
+			t, err := parseDateParam(v)
+			if err != nil {
+				c.responder.Error(w, http.StatusBadRequest, "invalid created_to")
+				return
+			}
+			to = t
+		}
+		if to.Before(from) {
+			c.responder.Error(w, http.StatusBadRequest, "created_from must not be after created_to")
+			return
+		}
+
+		users, err := c.users.ListUsersCreatedBetween(r.Context(), from, to, limit, offset)
+		if err != nil {
+			c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		c.responder.Respond(w, http.StatusOK, users)
+		return
+	}
+
+	if r.URL.Query().Has("cursor") {
+		cursor, err := strconv.Atoi(r.URL.Query().Get("cursor"))
+		if err != nil || cursor < 0 {
+			c.responder.Error(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+
+		users, err := c.users.ListUsersAfter(r.Context(), cursor, limit)
+		if err != nil {
+			c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+
+		page := CursorPage{Users: users}
+		if len(users) == limit {
+			page.NextCursor = users[len(users)-1].ID
+		}
+		c.responder.Respond(w, http.StatusOK, page)
+		return
+	}
+
+	users, err := c.users.ListUsers(r.Context(), limit, offset)
+	if err != nil {
+		c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.responder.Respond(w, http.StatusOK, users)
+}
+
+// parseDateParam разбирает дату из параметра запроса: RFC3339 с временем
+// или только дата (2006-01-02, полночь UTC).
+func parseDateParam(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+// ExportCSV отдаёт список пользователей CSV-файлом, постранично выгружая
+// данные из репозитория, чтобы не держать всю таблицу в памяти.
+//
+// @Summary      Экспорт пользователей в CSV
+// @Tags         users
+// @Produce      text/csv
+// @Success      200 {string} string "CSV с колонками id, email, created_at, updated_at"
+// @Failure      403 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/users/export.csv [get]
+func (c *UserController) ExportCSV(w http.ResponseWriter, r *http.Request) {
+	email, _ := middleware.EmailFromContext(r.Context())
+	if !c.cfg.IsAdmin(email) {
+		c.responder.Error(w, http.StatusForbidden, "admin access required")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="users.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"id", "email", "created_at", "updated_at"})
+
+	cursor := 0
+	for {
+		users, err := c.users.ListUsersAfter(r.Context(), cursor, c.cfg.MaxPageSize)
+		if err != nil {
+			// Заголовок уже отправлен — просто обрываем выгрузку.
+			break
+		}
+		for _, u := range users {
+			_ = cw.Write([]string{
+				strconv.Itoa(u.ID),
+				u.Email,
+				u.CreatedAt.Format(time.RFC3339),
+				u.UpdatedAt.Format(time.RFC3339),
+			})
+		}
+		cw.Flush()
+		if len(users) < c.cfg.MaxPageSize {
+			break
+		}
+		cursor = users[len(users)-1].ID
+	}
+}
+
+// ExportJSON отдаёт всех пользователей потоковым JSON-массивом: страницы
+// читаются из репозитория по курсору и уходят клиенту по мере чтения, не
+// накапливаясь в памяти.
+//
+// @Summary      Экспорт пользователей в JSON
+// @Tags         users
+// @Produce      json
+// @Success      200 {array} entity.User
+// @Failure      403 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/users/export.json [get]
+func (c *UserController) ExportJSON(w http.ResponseWriter, r *http.Request) {
+	email, _ := middleware.EmailFromContext(r.Context())
+	if !c.cfg.IsAdmin(email) {
+		c.responder.Error(w, http.StatusForbidden, "admin access required")
+		return
+	}
+
+	items := make(chan interface{})
+	go func() {
+		defer close(items)
+		cursor := 0
+		for {
+			users, err := c.users.ListUsersAfter(r.Context(), cursor, c.cfg.MaxPageSize)
+			if err != nil {
+				// Массив уже начат — просто обрываем выгрузку.
+				return
+			}
+			for _, u := range users {
+				select {
+				case items <- u:
+				case <-r.Context().Done():
+					return
+				}
+			}
+			if len(users) < c.cfg.MaxPageSize {
+				return
+			}
+			cursor = users[len(users)-1].ID
+		}
+	}()
+
+	c.responder.RespondStream(w, http.StatusOK, items)
+}
+
+// ChangeEmailRequest — тело запроса смены email.
+type ChangeEmailRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// VerifyEmailRequest — тело запроса подтверждения смены email.
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// RequestEmailChange сохраняет новый email текущего пользователя как
+// отложенный и инициирует отправку токена подтверждения.
+//
+// @Summary      Запрос смены email
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request body ChangeEmailRequest true "Новый email"
+// @Success      202 {object} responder.ErrorResponse
+// @Failure      400 {object} responder.ErrorResponse
+// @Failure      409 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/users/me/email [post]
+func (c *UserController) RequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	var req ChangeEmailRequest
+	if !c.responder.DecodeAndValidate(w, r, &req) {
+		return
+	}
+
+	email, _ := middleware.EmailFromContext(r.Context())
+	if err := c.users.RequestEmailChange(r.Context(), email, req.Email); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrAlreadyExists):
+			c.responder.Error(w, http.StatusConflict, "email already in use")
+		case errors.Is(err, repository.ErrNotFound):
+			c.responder.Error(w, http.StatusNotFound, "user not found")
+		default:
+			c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	c.responder.Respond(w, http.StatusAccepted, map[string]string{"status": "verification sent"})
+}
+
+// VerifyEmailChange подтверждает отложенную смену email по токену.
+//
+// @Summary      Подтверждение смены email
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request body VerifyEmailRequest true "Токен подтверждения"
+// @Success      200 {object} responder.ErrorResponse
+// @Failure      400 {object} responder.ErrorResponse
+// @Failure      409 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/users/me/email/verify [post]
+func (c *UserController) VerifyEmailChange(w http.ResponseWriter, r *http.Request) {
+	var req VerifyEmailRequest
+	if !c.responder.DecodeAndValidate(w, r, &req) {
+		return
+	}
+
+	email, _ := middleware.EmailFromContext(r.Context())
+	if err := c.users.ConfirmEmailChange(r.Context(), email, req.Token); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrAlreadyExists):
+			c.responder.Error(w, http.StatusConflict, "email already in use")
+		case errors.Is(err, repository.ErrNotFound):
+			c.responder.Error(w, http.StatusBadRequest, "invalid verification token")
+		default:
+			c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	c.responder.Respond(w, http.StatusOK, map[string]string{"status": "email updated"})
+}
+
+// GetUser возвращает пользователя по идентификатору.
+//
+// @Summary      Пользователь по ID
+// @Tags         users
+// @Produce      json
+// @Param        id path int true "ID пользователя"
+// @Success      200 {object} entity.User
+// @Failure      404 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/users/{id} [get]
+func (c *UserController) GetUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		c.responder.Error(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	user, err := c.users.GetUser(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.responder.Error(w, http.StatusNotFound, "user not found")
+			return
+		}
+		c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.responder.Respond(w, http.StatusOK, user)
+}
+
+// UpdateUserRequest — тело полного обновления пользователя. В отличие от
+// PATCH оба поля обязательны; пароль передаётся открытым текстом и
+// хэшируется сервисом.
+type UpdateUserRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// UpdateUser полностью обновляет пользователя.
+//
+// @Summary      Обновление пользователя
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        id      path int               true "ID пользователя"
+// @Param        request body UpdateUserRequest true "Новые данные"
+// @Success      200 {object} entity.User
+// @Failure      400 {object} responder.ErrorResponse
+// @Failure      404 {object} responder.ErrorResponse
+// @Failure      409 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/users/{id} [put]
+func (c *UserController) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		c.responder.Error(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	if !c.authorizeModify(w, r, id) {
+		return
+	}
+
+	var req UpdateUserRequest
+	if !c.responder.DecodeAndValidate(w, r, &req) {
+		return
+	}
+
+	// Полное обновление идёт тем же путём load-and-merge, что и частичное:
+	// сущность загружается из базы, поэтому служебные поля (хэш пароля,
+	// метки времени) не приходят из тела запроса.
+	user, err := c.users.PatchUser(r.Context(), id, req.Email, req.Password)
+	if err != nil {
+		c.respondUserWriteError(w, err)
+		return
+	}
+
+	c.responder.Respond(w, http.StatusOK, user)
+}
+
+// respondUserWriteError переводит ошибку записи пользователя в HTTP-ответ.
+func (c *UserController) respondUserWriteError(w http.ResponseWriter, err error) {
+	var verr *entity.ValidationError
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		c.responder.Error(w, http.StatusNotFound, "user not found")
+	case errors.Is(err, repository.ErrAlreadyExists):
+		c.responder.Error(w, http.StatusConflict, "email already in use")
+	case errors.As(err, &verr):
+		c.responder.Error(w, http.StatusBadRequest, verr.Error())
+	default:
+		c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+// PatchUserRequest — тело частичного обновления: только изменяемые поля.
+type PatchUserRequest struct {
+	Email    string `json:"email" validate:"omitempty,email"`
+	Password string `json:"password" validate:"omitempty,min=8"`
+}
+
+// PatchUser частично обновляет пользователя: непереданные поля сохраняют
+// прежние значения.
+//
+// @Summary      Частичное обновление пользователя
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        id      path int              true "ID пользователя"
+// @Param        request body PatchUserRequest true "Изменяемые поля"
+// @Success      200 {object} entity.User
+// @Failure      404 {object} responder.ErrorResponse
+// @Failure      409 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/users/{id} [patch]
+func (c *UserController) PatchUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		c.responder.Error(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	if !c.authorizeModify(w, r, id) {
+		return
+	}
+
+	var req PatchUserRequest
+	if !c.responder.DecodeAndValidate(w, r, &req) {
+		return
+	}
+
+	user, err := c.users.PatchUser(r.Context(), id, req.Email, req.Password)
+	if err != nil {
+		c.respondUserWriteError(w, err)
+		return
+	}
+
+	c.responder.Respond(w, http.StatusOK, user)
+}
+
+// SetLocation устанавливает местоположение текущего пользователя: координаты
+// обратно геокодируются, полученные город и улица сохраняются в профиле.
+//
+// @Summary      Установить местоположение пользователя
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request body GeocodeRequest true "Координаты"
+// @Success      200 {object} entity.Address
+// @Failure      400 {object} responder.ErrorResponse
+// @Failure      404 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/users/me/location [post]
+func (c *UserController) SetLocation(w http.ResponseWriter, r *http.Request) {
+	email, ok := middleware.EmailFromContext(r.Context())
+	if !ok {
+		c.responder.Error(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req GeocodeRequest
+	if !c.responder.DecodeAndValidate(w, r, &req) {
+		return
+	}
+
+	user, err := c.users.GetUserByEmail(r.Context(), email)
+	if err != nil {
+		c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	addresses, err := c.geo.GeoCode(req.Lat, req.Lon)
+	if err != nil {
+		c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if len(addresses) == 0 {
+		c.responder.Error(w, http.StatusNotFound, "no address found for coordinates")
+		return
+	}
+
+	address := addresses[0]
+	if err := c.users.SetUserLocation(r.Context(), user.ID, address.City, address.Street); err != nil {
+		c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.responder.Respond(w, http.StatusOK, address)
+}
+
+// authorizeModify проверяет право текущего пользователя менять пользователя
+// id: администратор может любого, остальные — только собственный аккаунт
+// (владелец определяется по email из токена). При отказе пишет 403 и
+// возвращает false.
+func (c *UserController) authorizeModify(w http.ResponseWriter, r *http.Request, id int) bool {
+	email, ok := middleware.EmailFromContext(r.Context())
+	if !ok {
+		c.responder.Error(w, http.StatusUnauthorized, "unauthorized")
+		return false
+	}
+	if c.cfg.IsAdmin(email) {
+		return true
+	}
+
+	actor, err := c.users.GetUserByEmail(r.Context(), email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.responder.Error(w, http.StatusForbidden, "forbidden")
+			return false
+		}
+		c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+		return false
+	}
+	if actor.ID != id {
+		c.responder.Error(w, http.StatusForbidden, "forbidden")
+		return false
+	}
+	return true
+}
+
+// DeleteUserRequest — необязательное тело запроса на удаление.
+type DeleteUserRequest struct {
+	// Reason — причина деактивации, попадает в deleted_reason.
+	Reason string `json:"reason"`
+}
+
+// DeleteUser помечает пользователя удалённым.
+//
+// @Summary      Удаление пользователя
+// @Tags         users
+// @Param        id path int true "ID пользователя"
+// @Param        request body DeleteUserRequest false "Причина удаления"
+// @Success      204
+// @Failure      404 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/users/{id} [delete]
+func (c *UserController) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		c.responder.Error(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	if !c.authorizeModify(w, r, id) {
+		return
+	}
+
+	// Тело необязательно: DELETE без тела или с нечитаемым телом
+	// трактуется как удаление без причины.
+	var req DeleteUserRequest
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	if err := c.users.DeleteUser(r.Context(), id, req.Reason); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.responder.Error(w, http.StatusNotFound, "user not found")
+			return
+		}
+		c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.responder.Respond(w, http.StatusNoContent, nil)
+}
+
+// BulkDeleteRequest — тело запроса массового удаления пользователей.
+type BulkDeleteRequest struct {
+	IDs []int `json:"ids" validate:"required,min=1,max=100,dive,gt=0"`
+}
+
+// BulkDeleteResult — итог удаления одного пользователя из пакета.
+type BulkDeleteResult struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"` // "deleted" или "not_found"
+}
+
+// BulkDeleteResponse — поимённые итоги массового удаления.
+type BulkDeleteResponse struct {
+	Results []BulkDeleteResult `json:"results"`
+}
+
+// BulkDeleteUsers помечает удалёнными до 100 пользователей за раз одной
+// транзакцией и возвращает результат по каждому id.
+//
+// @Summary      Массовое удаление пользователей
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request body BulkDeleteRequest true "Список id (не более 100)"
+// @Success      200 {object} BulkDeleteResponse
+// @Failure      400 {object} responder.ErrorResponse
+// @Failure      403 {object} responder.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/users/bulk-delete [post]
+func (c *UserController) BulkDeleteUsers(w http.ResponseWriter, r *http.Request) {
+	email, _ := middleware.EmailFromContext(r.Context())
+	if !c.cfg.IsAdmin(email) {
+		c.responder.Error(w, http.StatusForbidden, "admin access required")
+		return
+	}
+
+	var req BulkDeleteRequest
+	if !c.responder.DecodeAndValidate(w, r, &req) {
+		return
+	}
+
+	deleted, err := c.users.DeleteUsersBatch(r.Context(), req.IDs)
+	if err != nil {
+		c.responder.Error(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	ok := make(map[int]bool, len(deleted))
+	for _, id := range deleted {
+		ok[id] = true
+	}
+	resp := BulkDeleteResponse{Results: make([]BulkDeleteResult, 0, len(req.IDs))}
+	for _, id := range req.IDs {
+		status := "not_found"
+		if ok[id] {
+			status = "deleted"
+		}
+		resp.Results = append(resp.Results, BulkDeleteResult{ID: id, Status: status})
+	}
+	c.responder.Respond(w, http.StatusOK, resp)
+}
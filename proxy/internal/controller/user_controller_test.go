@@ -0,0 +1,546 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/entity"
+)
+
+func seedUsers(t *testing.T, repo *fakeUserRepo, n int) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		err := repo.Create(context.Background(), &entity.User{
+			Email:        fmt.Sprintf("user%d@example.com", i),
+			PasswordHash: "hash",
+		})
+		if err != nil {
+			t.Fatalf("seed user %d: %v", i, err)
+		}
+	}
+}
+
+func listUsers(t *testing.T, router *chi.Mux, query string) []*entity.User {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users"+query, nil)
+	req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+
+	var users []*entity.User
+	if err := json.Unmarshal(rec.Body.Bytes(), &users); err != nil {
+		t.Fatalf("unmarshal users: %v", err)
+	}
+	return users
+}
+
+func TestListUsers_CursorWalksTable(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 25)
+
+	seen := make(map[int]bool)
+	cursor := 0
+	for page := 0; page < 10; page++ {
+		req := httptest.NewRequest(http.MethodGet,
+			fmt.Sprintf("/api/users?cursor=%d&limit=10", cursor), nil)
+		req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got %d, want 200", rec.Code)
+		}
+
+		var resp CursorPage
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("unmarshal page: %v", err)
+		}
+		for _, u := range resp.Users {
+			if seen[u.ID] {
+				t.Errorf("user %d returned twice: pages overlap", u.ID)
+			}
+			seen[u.ID] = true
+		}
+		if resp.NextCursor == 0 {
+			break
+		}
+		cursor = resp.NextCursor
+	}
+
+	if len(seen) != 25 {
+		t.Errorf("walked %d users, want all 25 without gaps", len(seen))
+	}
+}
+
+func TestListUsers_InvalidCursor(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 3)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users?cursor=abc", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("got %d, want 400 for invalid cursor", rec.Code)
+	}
+}
+
+func TestListUsers_IncludeDeleted(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 5)
+	if err := repo.Delete(context.Background(), 3, ""); err != nil {
+		t.Fatalf("delete user: %v", err)
+	}
+
+	t.Run("without flag deleted users are hidden", func(t *testing.T) {
+		users := listUsers(t, router, "")
+		if len(users) != 4 {
+			t.Errorf("got %d users, want 4", len(users))
+		}
+	})
+
+	t.Run("admin with flag sees deleted users", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/users?include_deleted=true", nil)
+		req.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", "admin@example.com"))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got %d, want 200", rec.Code)
+		}
+
+		var users []*entity.User
+		if err := json.Unmarshal(rec.Body.Bytes(), &users); err != nil {
+			t.Fatalf("unmarshal users: %v", err)
+		}
+		if len(users) != 5 {
+			t.Errorf("got %d users, want all 5", len(users))
+		}
+		deleted := 0
+		for _, u := range users {
+			if u.DeletedAt != nil {
+				deleted++
+			}
+		}
+		if deleted != 1 {
+			t.Errorf("got %d users with deleted_at, want 1", deleted)
+		}
+	})
+
+	t.Run("non-admin with flag gets 403", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/users?include_deleted=true", nil)
+		req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("got %d, want 403", rec.Code)
+		}
+	})
+}
+
+func TestListUsers_CreatedRange(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 3)
+
+	t.Run("range covering now returns users", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet,
+			"/api/users?created_from=2000-01-01&created_to=2100-01-01", nil)
+		req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+		}
+		var users []*entity.User
+		if err := json.Unmarshal(rec.Body.Bytes(), &users); err != nil {
+			t.Fatalf("unmarshal users: %v", err)
+		}
+		if len(users) != 3 {
+			t.Errorf("got %d users, want 3", len(users))
+		}
+	})
+
+	t.Run("range in the past is empty", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet,
+			"/api/users?created_from=2000-01-01&created_to=2000-01-08", nil)
+		req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got %d, want 200", rec.Code)
+		}
+		var users []*entity.User
+		if err := json.Unmarshal(rec.Body.Bytes(), &users); err != nil {
+			t.Fatalf("unmarshal users: %v", err)
+		}
+		if len(users) != 0 {
+			t.Errorf("got %d users, want 0", len(users))
+		}
+	})
+
+	t.Run("RFC3339 timestamps accepted", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet,
+			"/api/users?created_from=2000-01-01T00:00:00Z&created_to=2100-01-01T00:00:00Z", nil)
+		req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("inverted range is 400", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet,
+			"/api/users?created_from=2100-01-01&created_to=2000-01-01", nil)
+		req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got %d, want 400", rec.Code)
+		}
+	})
+
+	t.Run("garbage date is 400", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet,
+			"/api/users?created_from=yesterday", nil)
+		req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got %d, want 400", rec.Code)
+		}
+	})
+}
+
+func TestListUsers_LimitClamped(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 120)
+
+	users := listUsers(t, router, "?limit=10000")
+	if len(users) != 100 {
+		t.Errorf("got %d users, want limit clamped to 100", len(users))
+	}
+}
+
+func TestListUsers_LimitFallsBackToDefault(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 50)
+
+	for _, query := range []string{"?limit=-5", "?limit=0", ""} {
+		users := listUsers(t, router, query)
+		if len(users) != defaultLimit {
+			t.Errorf("query %q: got %d users, want default %d", query, len(users), defaultLimit)
+		}
+	}
+}
+
+func TestDeleteUser_ReasonPersistedAndSurfaced(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 2)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/users/1",
+		strings.NewReader(`{"reason":"fraudulent activity"}`))
+	req.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", "user0@example.com"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("got %d, want 204 (body: %s)", rec.Code, rec.Body.String())
+	}
+
+	u := repo.users[1]
+	if u.DeletedReason == nil || *u.DeletedReason != "fraudulent activity" {
+		t.Fatalf("reason not persisted: %+v", u.DeletedReason)
+	}
+
+	// Причина видна администратору в листинге с удалёнными.
+	listReq := httptest.NewRequest(http.MethodGet, "/api/users?include_deleted=true", nil)
+	listReq.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", "admin@example.com"))
+	listRec := httptest.NewRecorder()
+	router.ServeHTTP(listRec, listReq)
+
+	if listRec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200", listRec.Code)
+	}
+	var users []*entity.User
+	if err := json.Unmarshal(listRec.Body.Bytes(), &users); err != nil {
+		t.Fatalf("unmarshal users: %v", err)
+	}
+	found := false
+	for _, u := range users {
+		if u.ID == 1 && u.DeletedReason != nil && *u.DeletedReason == "fraudulent activity" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("deleted_reason not surfaced in admin listing")
+	}
+}
+
+func TestDeleteUser_NoBodyStillDeletes(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 1)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/users/1", nil)
+	req.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", "user0@example.com"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("got %d, want 204 (body: %s)", rec.Code, rec.Body.String())
+	}
+	if u := repo.users[1]; u.DeletedAt == nil || u.DeletedReason != nil {
+		t.Errorf("delete without body must not set a reason: %+v", u)
+	}
+}
+
+func TestSetLocation_PersistsResolvedAddress(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 1)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/users/me/location",
+		strings.NewReader(`{"lat":"55.7558","lon":"37.6173"}`))
+	req.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", "user0@example.com"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+
+	var addr entity.Address
+	if err := json.Unmarshal(rec.Body.Bytes(), &addr); err != nil {
+		t.Fatalf("unmarshal address: %v", err)
+	}
+	if addr.City != "Москва" || addr.Street != "Тверская" {
+		t.Errorf("unexpected resolved address: %+v", addr)
+	}
+
+	u := repo.users[1]
+	if u.LocationCity == nil || *u.LocationCity != "Москва" {
+		t.Errorf("city not persisted: %+v", u.LocationCity)
+	}
+	if u.LocationStreet == nil || *u.LocationStreet != "Тверская" {
+		t.Errorf("street not persisted: %+v", u.LocationStreet)
+	}
+}
+
+func TestSetLocation_InvalidCoordinates(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 1)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/users/me/location",
+		strings.NewReader(`{"lat":"not-a-number","lon":"37.6173"}`))
+	req.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", "user0@example.com"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("got %d, want 400 (body: %s)", rec.Code, rec.Body.String())
+	}
+	if u := repo.users[1]; u.LocationCity != nil {
+		t.Errorf("location must not be set on invalid input: %+v", u)
+	}
+}
+
+func TestBulkDeleteUsers_AllSuccess(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 3)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/users/bulk-delete",
+		strings.NewReader(`{"ids":[1,2,42]}`))
+	req.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", "admin@example.com"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+
+	var resp BulkDeleteResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	want := map[int]string{1: "deleted", 2: "deleted", 42: "not_found"}
+	if len(resp.Results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(resp.Results), len(want))
+	}
+	for _, res := range resp.Results {
+		if res.Status != want[res.ID] {
+			t.Errorf("id %d: got status %q, want %q", res.ID, res.Status, want[res.ID])
+		}
+	}
+
+	if repo.users[1].DeletedAt == nil || repo.users[2].DeletedAt == nil {
+		t.Error("users 1 and 2 must be soft-deleted")
+	}
+	if repo.users[3].DeletedAt != nil {
+		t.Error("user 3 was not requested and must stay")
+	}
+}
+
+func TestBulkDeleteUsers_CapViolation(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	ids := make([]string, 101)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i + 1)
+	}
+	body := `{"ids":[` + strings.Join(ids, ",") + `]}`
+
+	req := httptest.NewRequest(http.MethodPost, "/api/users/bulk-delete", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", "admin@example.com"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("got %d, want 400 for more than 100 ids", rec.Code)
+	}
+}
+
+func TestBulkDeleteUsers_NonAdmin(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/users/bulk-delete",
+		strings.NewReader(`{"ids":[1]}`))
+	req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("got %d, want 403", rec.Code)
+	}
+}
+
+func TestPatchUser_SelfAllowed(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 2)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/users/1",
+		strings.NewReader(`{"email":"renamed@example.com"}`))
+	req.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", "user0@example.com"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200 for self-update (body: %s)", rec.Code, rec.Body.String())
+	}
+	if repo.users[1].Email != "renamed@example.com" {
+		t.Errorf("email not updated: %q", repo.users[1].Email)
+	}
+}
+
+func TestPatchUser_OtherUserForbidden(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 2)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/users/2",
+		strings.NewReader(`{"email":"hijacked@example.com"}`))
+	req.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", "user0@example.com"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("got %d, want 403 for modifying another user", rec.Code)
+	}
+	if repo.users[2].Email != "user1@example.com" {
+		t.Errorf("user 2 must be untouched, got %q", repo.users[2].Email)
+	}
+}
+
+func TestPatchUser_AdminModifiesAnyone(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 2)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/users/2",
+		strings.NewReader(`{"email":"by-admin@example.com"}`))
+	req.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", "admin@example.com"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200 for admin update (body: %s)", rec.Code, rec.Body.String())
+	}
+	if repo.users[2].Email != "by-admin@example.com" {
+		t.Errorf("email not updated by admin: %q", repo.users[2].Email)
+	}
+}
+
+func TestUpdateUser_FullUpdate(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 1)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/users/1",
+		strings.NewReader(`{"email":"replaced@example.com","password":"new-password"}`))
+	req.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", "user0@example.com"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+	if repo.users[1].Email != "replaced@example.com" {
+		t.Errorf("email not updated: %q", repo.users[1].Email)
+	}
+	if err := bcrypt.CompareHashAndPassword(
+		[]byte(repo.users[1].PasswordHash), []byte("new-password")); err != nil {
+		t.Errorf("stored hash must match the new password: %v", err)
+	}
+}
+
+func TestUpdateUser_MissingPasswordRejected(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 1)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/users/1",
+		strings.NewReader(`{"email":"replaced@example.com"}`))
+	req.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", "user0@example.com"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("got %d, want 400 for PUT without password", rec.Code)
+	}
+	if repo.users[1].Email != "user0@example.com" {
+		t.Errorf("user must be untouched, got %q", repo.users[1].Email)
+	}
+}
+
+func TestDeleteUser_OtherUserForbidden(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 2)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/users/2", nil)
+	req.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", "user0@example.com"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("got %d, want 403", rec.Code)
+	}
+	if repo.users[2].DeletedAt != nil {
+		t.Error("user 2 must not be deleted")
+	}
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExportCSV(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 3)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/export.csv", nil)
+	req.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", "admin@example.com"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/csv") {
+		t.Errorf("got Content-Type %q, want text/csv", rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Header().Get("Content-Disposition"), "users.csv") {
+		t.Errorf("got Content-Disposition %q, want attachment users.csv", rec.Header().Get("Content-Disposition"))
+	}
+
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse CSV: %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("got %d records, want header + 3 rows", len(records))
+	}
+
+	header := strings.Join(records[0], ",")
+	if header != "id,email,created_at,updated_at" {
+		t.Errorf("got header %q, want id,email,created_at,updated_at", header)
+	}
+	if records[1][0] != "1" || records[1][1] != "user0@example.com" {
+		t.Errorf("unexpected first row: %v", records[1])
+	}
+	if records[1][2] == "" || records[1][3] == "" {
+		t.Errorf("timestamps missing in row: %v", records[1])
+	}
+}
+
+func TestExportCSV_NonAdmin(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/export.csv", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("got %d, want 403", rec.Code)
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedUsers(t, repo, 3)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/export.json", nil)
+	req.Header.Set("Authorization", "Bearer "+testTokenFor(t, "test-secret", "admin@example.com"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got Content-Type %q, want application/json", ct)
+	}
+
+	var users []struct {
+		ID    int    `json:"id"`
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &users); err != nil {
+		t.Fatalf("parse JSON export: %v (body: %s)", err, rec.Body.String())
+	}
+	if len(users) != 3 {
+		t.Fatalf("got %d users, want 3", len(users))
+	}
+	if users[0].ID != 1 || users[0].Email != "user0@example.com" {
+		t.Errorf("unexpected first user: %+v", users[0])
+	}
+}
+
+func TestExportJSON_NonAdmin(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/export.json", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken(t, "test-secret"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("got %d, want 403", rec.Code)
+	}
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouter_Version(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/version", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200", rec.Code)
+	}
+
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal body: %v", err)
+	}
+
+	for _, field := range []string{"version", "commit", "built_at", "go_version"} {
+		if body[field] == "" {
+			t.Errorf("field %q is missing or empty", field)
+		}
+	}
+	if body["version"] != "dev" {
+		t.Errorf("version = %q, want dev by default", body["version"])
+	}
+}
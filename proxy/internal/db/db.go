@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/config"
+)
+
+// SQLAdapter — обёртка над подключением к Postgres.
+type SQLAdapter struct {
+	DB *sqlx.DB
+}
+
+// NewSQLAdapter открывает подключение к базе и проверяет его.
+func NewSQLAdapter(cfg *config.Config) (*SQLAdapter, error) {
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
+
+	conn, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("connect to db: %w", err)
+	}
+	return &SQLAdapter{DB: conn}, nil
+}
+
+// Ping проверяет доступность базы.
+func (a *SQLAdapter) Ping(ctx context.Context) error {
+	return a.DB.PingContext(ctx)
+}
+
+// Close закрывает подключение.
+func (a *SQLAdapter) Close() error {
+	return a.DB.Close()
+}
@@ -0,0 +1,14 @@
+package entity
+
+// Address — нормализованный адрес, отдаваемый клиентам API.
+type Address struct {
+	Value  string `json:"value"`
+	City   string `json:"city"`
+	Street string `json:"street"`
+	House  string `json:"house"`
+	Lat    string `json:"lat"`
+	Lon    string `json:"lon"`
+	// Precision — точность геокодирования по коду qc_geo Dadata:
+	// "exact", "near_house", "street", "settlement", "city" или "unknown".
+	Precision string `json:"precision,omitempty"`
+}
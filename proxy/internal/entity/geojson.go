@@ -0,0 +1,54 @@
+package entity
+
+import "strconv"
+
+// GeoJSONGeometry — геометрия Point в формате GeoJSON (координаты [lon, lat]).
+type GeoJSONGeometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+// GeoJSONFeature — объект Feature с адресом в свойствах. Geometry равна null,
+// если у адреса нет координат.
+type GeoJSONFeature struct {
+	Type       string            `json:"type"`
+	Geometry   *GeoJSONGeometry  `json:"geometry"`
+	Properties map[string]string `json:"properties"`
+}
+
+// GeoJSONFeatureCollection — коллекция адресов в формате GeoJSON.
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+// ToGeoJSON собирает FeatureCollection из адресов. Адреса без разборчивых
+// координат попадают в коллекцию с geometry: null.
+func ToGeoJSON(addrs []*Address) GeoJSONFeatureCollection {
+	features := make([]GeoJSONFeature, 0, len(addrs))
+	for _, a := range addrs {
+		feature := GeoJSONFeature{
+			Type: "Feature",
+			Properties: map[string]string{
+				"value":  a.Value,
+				"city":   a.City,
+				"street": a.Street,
+				"house":  a.House,
+			},
+		}
+		if a.Precision != "" {
+			feature.Properties["precision"] = a.Precision
+		}
+
+		lon, lonErr := strconv.ParseFloat(a.Lon, 64)
+		lat, latErr := strconv.ParseFloat(a.Lat, 64)
+		if lonErr == nil && latErr == nil {
+			feature.Geometry = &GeoJSONGeometry{
+				Type:        "Point",
+				Coordinates: []float64{lon, lat},
+			}
+		}
+		features = append(features, feature)
+	}
+	return GeoJSONFeatureCollection{Type: "FeatureCollection", Features: features}
+}
@@ -0,0 +1,45 @@
+package entity
+
+import "testing"
+
+func TestToGeoJSON(t *testing.T) {
+	addrs := []*Address{
+		{Value: "Москва, Тверская 1", City: "Москва", Street: "Тверская", House: "1", Lat: "55.7558", Lon: "37.6173"},
+		{Value: "Без координат", City: "Москва", Street: "Арбат"},
+	}
+
+	fc := ToGeoJSON(addrs)
+
+	if fc.Type != "FeatureCollection" {
+		t.Errorf("got type %q, want FeatureCollection", fc.Type)
+	}
+	if len(fc.Features) != 2 {
+		t.Fatalf("got %d features, want 2", len(fc.Features))
+	}
+
+	withGeom := fc.Features[0]
+	if withGeom.Geometry == nil {
+		t.Fatal("feature with coordinates must have geometry")
+	}
+	if withGeom.Geometry.Type != "Point" {
+		t.Errorf("got geometry type %q, want Point", withGeom.Geometry.Type)
+	}
+	// GeoJSON требует порядок [lon, lat].
+	if got := withGeom.Geometry.Coordinates; len(got) != 2 || got[0] != 37.6173 || got[1] != 55.7558 {
+		t.Errorf("got coordinates %v, want [37.6173 55.7558]", got)
+	}
+	if withGeom.Properties["city"] != "Москва" {
+		t.Errorf("got city %q, want Москва", withGeom.Properties["city"])
+	}
+
+	if fc.Features[1].Geometry != nil {
+		t.Error("feature without coordinates must have null geometry")
+	}
+}
+
+func TestToGeoJSON_Empty(t *testing.T) {
+	fc := ToGeoJSON(nil)
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 0 {
+		t.Errorf("got %+v, want empty FeatureCollection", fc)
+	}
+}
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"fmt"
+	"net/mail"
+	"time"
+)
+
+// ValidationError — нарушение инварианта сущности перед записью в базу.
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
+}
+
+// User — учётная запись пользователя сервиса.
+type User struct {
+	ID           int    `db:"id" json:"id"`
+	Email        string `db:"email" json:"email"`
+	PasswordHash string `db:"password_hash" json:"-"`
+	// PendingEmail — новый email, ожидающий подтверждения по токену.
+	PendingEmail      *string    `db:"pending_email" json:"-"`
+	PendingEmailToken *string    `db:"pending_email_token" json:"-"`
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time  `db:"updated_at" json:"updated_at"`
+	DeletedAt         *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	// DeletedReason — причина деактивации, указанная при мягком удалении.
+	DeletedReason *string `db:"deleted_reason" json:"deleted_reason,omitempty"`
+	// LocationCity и LocationStreet — адрес пользователя, установленный
+	// через «задать моё местоположение».
+	LocationCity   *string `db:"location_city" json:"location_city,omitempty"`
+	LocationStreet *string `db:"location_street" json:"location_street,omitempty"`
+}
+
+// Validate проверяет инварианты пользователя перед записью в базу.
+func (u *User) Validate() error {
+	if u.Email == "" {
+		return &ValidationError{Field: "email", Reason: "must not be empty"}
+	}
+	if _, err := mail.ParseAddress(u.Email); err != nil {
+		return &ValidationError{Field: "email", Reason: "must be a valid email address"}
+	}
+	if u.PasswordHash == "" {
+		return &ValidationError{Field: "password_hash", Reason: "must not be empty"}
+	}
+	return nil
+}
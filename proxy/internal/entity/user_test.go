@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUser_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		user      User
+		wantField string
+	}{
+		{"valid", User{Email: "user@example.com", PasswordHash: "hash"}, ""},
+		{"empty email", User{PasswordHash: "hash"}, "email"},
+		{"malformed email", User{Email: "not-an-email", PasswordHash: "hash"}, "email"},
+		{"empty password hash", User{Email: "user@example.com"}, "password_hash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.user.Validate()
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("got %v, want ValidationError", err)
+			}
+			if verr.Field != tt.wantField {
+				t.Errorf("got field %q, want %q", verr.Field, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package hugo
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// defaultInterval — период между контрольными записями.
+	defaultInterval = 5 * time.Second
+	// defaultMaxFailures — число подряд неудачных записей, после которого
+	// воркер останавливается: проблема явно не временная.
+	defaultMaxFailures = 5
+)
+
+// WorkerTest периодически пишет служебный файл в каталог статики Hugo,
+// чтобы проверить, что том документации примонтирован и доступен на запись.
+// После defaultMaxFailures ошибок подряд воркер останавливается с одной
+// итоговой записью в лог, не зашумляя его бесконечными повторами.
+func WorkerTest(dir string, logger *slog.Logger) {
+	workerTest(dir, defaultInterval, defaultMaxFailures, logger)
+}
+
+func workerTest(dir string, interval time.Duration, maxFailures int, logger *slog.Logger) {
+	failures := 0
+	for {
+		content := []byte("updated: " + time.Now().Format(time.RFC3339) + "\n")
+		if err := os.WriteFile(filepath.Join(dir, "worker_test.txt"), content, 0644); err != nil {
+			failures++
+			if failures >= maxFailures {
+				logger.Error("hugo worker stopped: persistent write failures",
+					"dir", dir,
+					"consecutive_failures", failures,
+					"error", err,
+				)
+				return
+			}
+			logger.Error("hugo worker write", "error", err)
+		} else {
+			failures = 0
+		}
+		time.Sleep(interval)
+	}
+}
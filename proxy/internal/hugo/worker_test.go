@@ -0,0 +1,43 @@
+package hugo
+
+import (
+	"log/slog"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWorkerTest_StopsAfterPersistentFailures(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(httptest.NewRecorder(), nil))
+	badDir := filepath.Join(t.TempDir(), "no", "such", "dir")
+
+	done := make(chan struct{})
+	go func() {
+		workerTest(badDir, time.Millisecond, 3, logger)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("worker did not stop after persistent write failures")
+	}
+}
+
+func TestWorkerTest_SuccessResetsFailureCounter(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(httptest.NewRecorder(), nil))
+	dir := t.TempDir()
+
+	done := make(chan struct{})
+	go func() {
+		workerTest(dir, time.Millisecond, 3, logger)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("worker stopped although writes succeed")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,84 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/smtp"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/config"
+)
+
+// Mailer отправляет письма пользователям сервиса.
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// NewFromConfig выбирает реализацию по конфигурации: при заданном SMTP_HOST —
+// отправка через SMTP, иначе письма только пишутся в журнал.
+func NewFromConfig(cfg *config.Config, logger *slog.Logger) Mailer {
+	if cfg.SMTPHost != "" {
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+	}
+	return NewLogMailer(logger)
+}
+
+// LogMailer — реализация по умолчанию: вместо отправки пишет письмо в журнал.
+// Используется в окружениях без настроенного SMTP.
+type LogMailer struct {
+	logger *slog.Logger
+}
+
+// NewLogMailer создаёт mailer, пишущий письма в журнал.
+func NewLogMailer(logger *slog.Logger) *LogMailer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &LogMailer{logger: logger}
+}
+
+// Send пишет письмо в журнал и всегда завершается успешно.
+func (m *LogMailer) Send(_ context.Context, to, subject, body string) error {
+	m.logger.Info("mail not sent: no SMTP configured",
+		"to", to,
+		"subject", subject,
+		"body", body,
+	)
+	return nil
+}
+
+// SMTPMailer отправляет письма через SMTP-сервер.
+type SMTPMailer struct {
+	addr     string
+	from     string
+	username string
+	password string
+	host     string
+}
+
+// NewSMTPMailer создаёт mailer поверх SMTP-сервера. При пустом username
+// отправка идёт без аутентификации.
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{
+		addr:     host + ":" + port,
+		from:     from,
+		username: username,
+		password: password,
+		host:     host,
+	}
+}
+
+// Send отправляет письмо получателю to.
+func (m *SMTPMailer) Send(_ context.Context, to, subject, body string) error {
+	msg := []byte(fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n",
+		m.from, to, subject, body))
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+	if err := smtp.SendMail(m.addr, auth, m.from, []string{to}, msg); err != nil {
+		return fmt.Errorf("send mail to %s: %w", to, err)
+	}
+	return nil
+}
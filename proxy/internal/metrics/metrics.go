@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry — собственный реестр метрик сервиса. Отдельный от глобального,
+// чтобы не зависеть от чужих регистраций и не делить счётчики между тестами.
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+var (
+	// HTTPRequestsTotal — счётчик входящих HTTP-запросов.
+	HTTPRequestsTotal = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Количество HTTP-запросов.",
+	}, []string{"method", "path", "status", "client"})
+
+	// HTTPRequestDuration — гистограмма длительности обработки запросов.
+	HTTPRequestDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Длительность обработки HTTP-запросов.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "path"})
+
+	externalAPIRequestsTotal = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "external_api_requests_total",
+		Help: "Количество запросов к внешним API.",
+	}, []string{"api", "outcome"})
+
+	externalAPIDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "external_api_request_duration_seconds",
+		Help:    "Длительность запросов к внешним API.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"api"})
+
+	// APIErrorsTotal — счётчик ответов API с ошибкой по статусам.
+	APIErrorsTotal = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "api_errors_total",
+		Help: "Количество ответов API с ошибкой по статусам.",
+	}, []string{"status"})
+
+	// AuthLoginAttemptsTotal — счётчик попыток входа по результату:
+	// success, invalid_password, user_not_found.
+	AuthLoginAttemptsTotal = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_login_attempts_total",
+		Help: "Количество попыток входа по результату.",
+	}, []string{"result"})
+
+	// UserRegistrationsTotal — счётчик успешных регистраций.
+	UserRegistrationsTotal = promauto.With(Registry).NewCounter(prometheus.CounterOpts{
+		Name: "user_registrations_total",
+		Help: "Количество успешных регистраций пользователей.",
+	})
+
+	// UserRegistrationErrorsTotal — счётчик неудачных регистраций
+	// по причинам: duplicate, validation, internal.
+	UserRegistrationErrorsTotal = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "user_registration_errors_total",
+		Help: "Количество неудачных регистраций по причинам.",
+	}, []string{"reason"})
+
+	// GeoRequestDuration — длительность геозапросов целиком, включая кэш:
+	// метка cache_result (hit/miss) разделяет быстрый путь из кэша и
+	// медленный с походом к провайдеру.
+	GeoRequestDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "geo_request_duration_seconds",
+		Help:    "Длительность геозапросов с учётом кэша.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"operation", "cache_result"})
+
+	// ServiceReady — готовность сервиса по последней проверке /readyz:
+	// 1 — все зависимости доступны, 0 — нет.
+	ServiceReady = promauto.With(Registry).NewGauge(prometheus.GaugeOpts{
+		Name: "service_ready",
+		Help: "Готовность сервиса по последней проверке readiness (1/0).",
+	})
+)
+
+// Значения метки outcome для запросов к внешним API.
+const (
+	OutcomeSuccess     = "success"
+	OutcomeError       = "error"
+	OutcomeTimeout     = "timeout"
+	OutcomeRateLimited = "rate_limited"
+)
+
+// ObserveExternalAPIRequest фиксирует запрос к внешнему API, его длительность
+// и исход (success, error, timeout, rate_limited).
+func ObserveExternalAPIRequest(api string, d time.Duration, outcome string) {
+	externalAPIRequestsTotal.WithLabelValues(api, outcome).Inc()
+	externalAPIDuration.WithLabelValues(api).Observe(d.Seconds())
+}
+
+// Handler возвращает HTTP-обработчик для /metrics поверх нашего реестра.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
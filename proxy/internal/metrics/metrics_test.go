@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func scrape(t *testing.T) string {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200", rec.Code)
+	}
+	return rec.Body.String()
+}
+
+func TestHandler_ExportsOwnMetricsFromCustomRegistry(t *testing.T) {
+	HTTPRequestsTotal.WithLabelValues("GET", "/test", "200", "api").Inc()
+
+	body := scrape(t)
+	if !strings.Contains(body, "http_requests_total") {
+		t.Error("http_requests_total missing from custom registry output")
+	}
+}
+
+func TestRegistry_DuplicateRegistrationDoesNotPanic(t *testing.T) {
+	err := Registry.Register(HTTPRequestsTotal)
+
+	var are prometheus.AlreadyRegisteredError
+	if !errors.As(err, &are) {
+		t.Errorf("got %v, want AlreadyRegisteredError", err)
+	}
+}
+
+func TestObserveExternalAPIRequest_OutcomeLabel(t *testing.T) {
+	successBefore := testutil.ToFloat64(externalAPIRequestsTotal.WithLabelValues("test_api", OutcomeSuccess))
+	errorBefore := testutil.ToFloat64(externalAPIRequestsTotal.WithLabelValues("test_api", OutcomeError))
+
+	ObserveExternalAPIRequest("test_api", time.Millisecond, OutcomeSuccess)
+	ObserveExternalAPIRequest("test_api", time.Millisecond, OutcomeError)
+
+	if got := testutil.ToFloat64(externalAPIRequestsTotal.WithLabelValues("test_api", OutcomeSuccess)); got != successBefore+1 {
+		t.Errorf("success counter = %v, want %v", got, successBefore+1)
+	}
+	if got := testutil.ToFloat64(externalAPIRequestsTotal.WithLabelValues("test_api", OutcomeError)); got != errorBefore+1 {
+		t.Errorf("error counter = %v, want %v", got, errorBefore+1)
+	}
+	if got := testutil.ToFloat64(externalAPIRequestsTotal.WithLabelValues("test_api", OutcomeTimeout)); got != 0 {
+		t.Errorf("timeout counter = %v, want 0", got)
+	}
+}
+
+func TestInstrumentRoundTripper_RecordsHostAndOutcome(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/limited" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := &http.Client{Transport: InstrumentRoundTripper(nil)}
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	for _, tc := range []struct {
+		name    string
+		path    string
+		outcome string
+	}{
+		{"success", "/", OutcomeSuccess},
+		{"rate limited", "/limited", OutcomeRateLimited},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			before := testutil.ToFloat64(externalAPIRequestsTotal.WithLabelValues(host, tc.outcome))
+
+			resp, err := client.Get(srv.URL + tc.path)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			after := testutil.ToFloat64(externalAPIRequestsTotal.WithLabelValues(host, tc.outcome))
+			if after != before+1 {
+				t.Errorf("counter{api=%q,outcome=%q} = %v, want %v", host, tc.outcome, after, before+1)
+			}
+		})
+	}
+}
+
+func TestInstrumentRoundTripper_TransportErrorIsError(t *testing.T) {
+	client := &http.Client{Transport: InstrumentRoundTripper(nil)}
+
+	// Порт 1 на loopback закрыт: соединение отклоняется и запрос
+	// завершается ошибкой транспорта.
+	const host = "127.0.0.1:1"
+	before := testutil.ToFloat64(externalAPIRequestsTotal.WithLabelValues(host, OutcomeError))
+
+	if _, err := client.Get("http://" + host + "/"); err == nil {
+		t.Fatal("expected transport error")
+	}
+
+	after := testutil.ToFloat64(externalAPIRequestsTotal.WithLabelValues(host, OutcomeError))
+	if after != before+1 {
+		t.Errorf("error counter = %v, want %v", after, before+1)
+	}
+}
+
+func TestHandler_ExportsGoRuntimeMetrics(t *testing.T) {
+	body := scrape(t)
+
+	for _, metric := range []string{"go_goroutines", "go_gc_duration_seconds", "process_cpu_seconds_total"} {
+		if !strings.Contains(body, metric) {
+			t.Errorf("metric %q missing from /metrics output", metric)
+		}
+	}
+}
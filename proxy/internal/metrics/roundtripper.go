@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// metricsRoundTripper учитывает исходящие HTTP-запросы в метриках внешних
+// API: метка api — хост назначения, исход выводится из ошибки и статуса.
+type metricsRoundTripper struct {
+	next http.RoundTripper
+}
+
+// InstrumentRoundTripper оборачивает транспорт учётом исходящих запросов.
+// Время замеряется вокруг всего обмена, включая установку соединения и
+// TLS-рукопожатие, поэтому точнее ручных замеров вокруг client.Do. nil
+// означает http.DefaultTransport.
+func InstrumentRoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &metricsRoundTripper{next: next}
+}
+
+func (t *metricsRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	ObserveExternalAPIRequest(req.URL.Host, time.Since(start), outboundOutcome(resp, err))
+	return resp, err
+}
+
+// outboundOutcome классифицирует результат исходящего запроса: сетевые
+// таймауты — timeout, прочие ошибки транспорта и статусы 4xx/5xx — error,
+// 429 — rate_limited.
+func outboundOutcome(resp *http.Response, err error) string {
+	if err != nil {
+		var netErr net.Error
+		if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+			return OutcomeTimeout
+		}
+		return OutcomeError
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return OutcomeRateLimited
+	case resp.StatusCode >= http.StatusBadRequest:
+		return OutcomeError
+	default:
+		return OutcomeSuccess
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// StrictAccept отклоняет запросы, чей заголовок Accept не допускает
+// application/json: сервис отвечает только JSON. Пустой Accept считается
+// согласием на любой тип. application/geo+json тоже пропускается — им
+// поиск адресов запрашивает ответ в формате GeoJSON.
+func StrictAccept(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !acceptsJSON(r.Header.Get("Accept")) {
+			writeJSONError(w, http.StatusNotAcceptable, "only application/json responses are supported")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func acceptsJSON(accept string) bool {
+	if accept == "" {
+		return true
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(part)
+		if i := strings.Index(mediaType, ";"); i >= 0 {
+			mediaType = strings.TrimSpace(mediaType[:i])
+		}
+		switch mediaType {
+		case "application/json", "application/geo+json", "application/*", "*/*":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStrictAccept(t *testing.T) {
+	handler := StrictAccept(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		accept string
+		want   int
+	}{
+		{"json", "application/json", http.StatusOK},
+		{"wildcard", "*/*", http.StatusOK},
+		{"json with params", "application/json; charset=utf-8", http.StatusOK},
+		{"geojson", "application/geo+json", http.StatusOK},
+		{"empty", "", http.StatusOK},
+		{"html only", "text/html", http.StatusNotAcceptable},
+		{"xml only", "application/xml", http.StatusNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("Accept %q: got %d, want %d", tt.accept, rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/metrics"
+)
+
+type ctxKey int
+
+const emailKey ctxKey = iota
+
+// EmailFromContext возвращает email из проверенного токена текущего запроса.
+func EmailFromContext(ctx context.Context) (string, bool) {
+	email, ok := ctx.Value(emailKey).(string)
+	return email, ok && email != ""
+}
+
+// AuthMiddleware проверяет JWT из заголовка Authorization.
+//
+// Отсутствующие или некорректно оформленные учётные данные дают 401,
+// предъявленный, но невалидный токен (подпись, срок действия) — 403.
+// Непустые issuer и audience дополнительно требуют совпадения клеймов
+// iss и aud.
+//
+// oldSecrets — прежние секреты подписи: токены, выпущенные до ротации
+// ключа, проверяются по ним, пока не истекут. Подпись сверяется сначала
+// с основным секретом, затем с прежними по порядку.
+func AuthMiddleware(secret string, oldSecrets []string, issuer, audience string) func(http.Handler) http.Handler {
+	var parserOpts []jwt.ParserOption
+	if issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(audience))
+	}
+	secrets := append([]string{secret}, oldSecrets...)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				writeJSONError(w, http.StatusUnauthorized, "missing credentials")
+				return
+			}
+			if !strings.HasPrefix(header, "Bearer ") {
+				header = "Bearer " + header
+			}
+
+			// После "Bearer " может оказаться пустая строка или несколько
+			// сегментов — берём первый, остальное игнорируем.
+			fields := strings.Fields(strings.TrimPrefix(header, "Bearer "))
+			if len(fields) == 0 {
+				writeJSONError(w, http.StatusUnauthorized, "missing token")
+				return
+			}
+			tokenString := fields[0]
+
+			var token *jwt.Token
+			var err error
+			for _, s := range secrets {
+				key := []byte(s)
+				token, err = jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+					if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+						return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+					}
+					return key, nil
+				}, parserOpts...)
+				// Следующий секрет имеет смысл пробовать только при
+				// несовпадении подписи; прочие ошибки от ключа не зависят.
+				if err == nil || !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+					break
+				}
+			}
+			if err != nil || !token.Valid {
+				writeJSONError(w, http.StatusForbidden, "invalid token")
+				return
+			}
+
+			// Сервис ключует пользователей по email, поэтому токен без
+			// этого клейма (например, выпущенный сторонней системой)
+			// не даёт доступа.
+			claims, ok := token.Claims.(jwt.MapClaims)
+			if !ok {
+				writeJSONError(w, http.StatusForbidden, "invalid token claims")
+				return
+			}
+			email, _ := claims["email"].(string)
+			if email == "" {
+				writeJSONError(w, http.StatusForbidden, "missing email claim")
+				return
+			}
+
+			r = r.WithContext(context.WithValue(r.Context(), emailKey, email))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeJSONError пишет ошибку в общем JSON-формате сервиса и, как и
+// responder.Error, учитывает её в api_errors_total: ответы middleware
+// (401/403/406/503) не должны выпадать из статистики ошибок.
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	metrics.APIErrorsTotal.WithLabelValues(strconv.Itoa(status)).Inc()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,240 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/metrics"
+)
+
+const testSecret = "test-secret"
+
+func authHandler(t *testing.T) http.Handler {
+	t.Helper()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return AuthMiddleware(testSecret, nil, "", "")(next)
+}
+
+func signToken(t *testing.T, secret string) string {
+	t.Helper()
+
+	claims := jwt.MapClaims{
+		"email": "test@example.com",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func signTokenWithClaims(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func TestAuthMiddleware_IssuerAudience(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := AuthMiddleware(testSecret, nil, "geoservis", "api")(next)
+
+	for _, tc := range []struct {
+		name     string
+		claims   jwt.MapClaims
+		wantCode int
+	}{
+		{"matching", jwt.MapClaims{
+			"email": "test@example.com",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"iss":   "geoservis",
+			"aud":   "api",
+		}, http.StatusOK},
+		{"wrong issuer", jwt.MapClaims{
+			"email": "test@example.com",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"iss":   "someone-else",
+			"aud":   "api",
+		}, http.StatusForbidden},
+		{"wrong audience", jwt.MapClaims{
+			"email": "test@example.com",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"iss":   "geoservis",
+			"aud":   "other-service",
+		}, http.StatusForbidden},
+		{"claims missing", jwt.MapClaims{
+			"email": "test@example.com",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		}, http.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+signTokenWithClaims(t, testSecret, tc.claims))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantCode {
+				t.Errorf("got %d, want %d", rec.Code, tc.wantCode)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_MissingEmailClaim(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		claims jwt.MapClaims
+	}{
+		{"no email claim", jwt.MapClaims{
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}},
+		{"empty email claim", jwt.MapClaims{
+			"email": "",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+signTokenWithClaims(t, testSecret, tc.claims))
+			rec := httptest.NewRecorder()
+			authHandler(t).ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("got %d, want 403 for a token without email", rec.Code)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_NoCredentials(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	authHandler(t).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("got %d, want 401 when no credentials are presented", rec.Code)
+	}
+}
+
+func TestAuthMiddleware_EmptyBearerToken(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	rec := httptest.NewRecorder()
+	authHandler(t).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("got %d, want 401 for empty bearer token", rec.Code)
+	}
+}
+
+func TestAuthMiddleware_MultipleSegments(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+signToken(t, testSecret)+" extra garbage")
+	rec := httptest.NewRecorder()
+	authHandler(t).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("got %d, want 200: first segment must be used as the token", rec.Code)
+	}
+}
+
+func TestAuthMiddleware_InvalidSignature(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+signToken(t, "wrong-secret"))
+	rec := httptest.NewRecorder()
+	authHandler(t).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("got %d, want 403 for invalid signature", rec.Code)
+	}
+}
+
+func TestAuthMiddleware_ValidToken(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+signToken(t, testSecret))
+	rec := httptest.NewRecorder()
+	authHandler(t).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("got %d, want 200", rec.Code)
+	}
+}
+
+func TestAuthMiddleware_KeyRotation(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := AuthMiddleware("new-secret", []string{"old-secret"}, "", "")(next)
+
+	for _, tc := range []struct {
+		name     string
+		secret   string
+		wantCode int
+	}{
+		{"primary key", "new-secret", http.StatusOK},
+		{"retired key still verifies", "old-secret", http.StatusOK},
+		{"unknown key rejected", "never-a-secret", http.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+signToken(t, tc.secret))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantCode {
+				t.Errorf("got %d, want %d", rec.Code, tc.wantCode)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_ExpiredTokenWithOldKeyStillRejected(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := AuthMiddleware("new-secret", []string{"old-secret"}, "", "")(next)
+
+	claims := jwt.MapClaims{
+		"email": "test@example.com",
+		"exp":   time.Now().Add(-time.Hour).Unix(),
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+signTokenWithClaims(t, "old-secret", claims))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("got %d, want 403 for an expired token regardless of key", rec.Code)
+	}
+}
+
+func TestWriteJSONError_CountsAPIErrors(t *testing.T) {
+	handler := authHandler(t)
+
+	before := testutil.ToFloat64(metrics.APIErrorsTotal.WithLabelValues("401"))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("got %d, want 401", rec.Code)
+	}
+	if got := testutil.ToFloat64(metrics.APIErrorsTotal.WithLabelValues("401")); got != before+1 {
+		t.Errorf("api_errors_total{401} = %v, want %v", got, before+1)
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import "net/http"
+
+// headResponseWriter отбрасывает тело ответа, сохраняя статус и заголовки.
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// HeadToGet обслуживает HEAD-запросы как соответствующий GET, отбрасывая
+// тело ответа: статус и заголовки совпадают с GET-ответом.
+func HeadToGet(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		clone := r.Clone(r.Context())
+		clone.Method = http.MethodGet
+		next.ServeHTTP(headResponseWriter{w}, clone)
+	})
+}
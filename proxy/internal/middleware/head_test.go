@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHeadToGet_MatchesGetWithoutBody(t *testing.T) {
+	handler := HeadToGet(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("handler got method %s, want GET", r.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+
+	getRec := httptest.NewRecorder()
+	handler.ServeHTTP(getRec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	headRec := httptest.NewRecorder()
+	handler.ServeHTTP(headRec, httptest.NewRequest(http.MethodHead, "/health", nil))
+
+	if headRec.Code != getRec.Code {
+		t.Errorf("HEAD status %d, GET status %d", headRec.Code, getRec.Code)
+	}
+	if got, want := headRec.Header().Get("Content-Type"), getRec.Header().Get("Content-Type"); got != want {
+		t.Errorf("HEAD Content-Type %q, GET %q", got, want)
+	}
+	if headRec.Body.Len() != 0 {
+		t.Errorf("HEAD body must be empty, got %q", headRec.Body.String())
+	}
+}
+
+func TestHeadToGet_PassesOtherMethodsThrough(t *testing.T) {
+	handler := HeadToGet(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("handler got method %s, want POST", r.Method)
+		}
+		w.Write([]byte("created"))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/register", nil))
+
+	if rec.Body.String() != "created" {
+		t.Errorf("POST body must pass through, got %q", rec.Body.String())
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// ConcurrencyLimit ограничивает число одновременно обрабатываемых запросов
+// буферизованным семафором. Когда все слоты заняты, запрос сразу получает 503
+// с Retry-After — это дешевле, чем копить горутины в очереди. При max <= 0
+// ограничение отключено.
+func ConcurrencyLimit(max int) func(http.Handler) http.Handler {
+	if max <= 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	sem := make(chan struct{}, max)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case sem <- struct{}{}:
+				// Слот освобождаем через defer, чтобы он вернулся и при
+				// панике обработчика.
+				defer func() { <-sem }()
+				next.ServeHTTP(w, r)
+			default:
+				w.Header().Set("Retry-After", "1")
+				writeJSONError(w, http.StatusServiceUnavailable, "too many concurrent requests")
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestConcurrencyLimit_RejectsOverLimit(t *testing.T) {
+	const limit = 2
+
+	release := make(chan struct{})
+	started := make(chan struct{}, limit)
+	handler := ConcurrencyLimit(limit)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			if rec.Code != http.StatusOK {
+				t.Errorf("held request got %d, want 200", rec.Code)
+			}
+		}()
+	}
+	for i := 0; i < limit; i++ {
+		<-started
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("got %d, want 503 when all slots are busy", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("503 response must carry Retry-After")
+	}
+
+	close(release)
+	wg.Wait()
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("got %d, want 200 after slots are freed", rec.Code)
+	}
+}
+
+func TestConcurrencyLimit_ReleasesSlotOnPanic(t *testing.T) {
+	handler := ConcurrencyLimit(1)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+
+	func() {
+		defer func() { _ = recover() }()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+
+	// После паники слот должен быть свободен: повторный запрос к тому же
+	// обработчику снова доходит до него (и снова паникует), а не получает 503.
+	rec := httptest.NewRecorder()
+	panicked := false
+	func() {
+		defer func() {
+			if recover() != nil {
+				panicked = true
+			}
+		}()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+	if !panicked {
+		t.Error("second request must reach the handler, slot was not released")
+	}
+}
+
+func TestConcurrencyLimit_ZeroDisablesLimit(t *testing.T) {
+	handler := ConcurrencyLimit(0)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("got %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// Maintenance — переключаемый на лету режим обслуживания: изменяющие запросы
+// к API получают 503, чтение продолжает работать.
+type Maintenance struct {
+	enabled atomic.Bool
+}
+
+// NewMaintenance создаёт режим обслуживания с начальным состоянием.
+func NewMaintenance(enabled bool) *Maintenance {
+	m := &Maintenance{}
+	m.enabled.Store(enabled)
+	return m
+}
+
+// Enabled сообщает, включён ли режим обслуживания.
+func (m *Maintenance) Enabled() bool {
+	return m.enabled.Load()
+}
+
+// SetEnabled включает или выключает режим обслуживания.
+func (m *Maintenance) SetEnabled(v bool) {
+	m.enabled.Store(v)
+}
+
+// Middleware возвращает 503 с Retry-After для POST/PUT/PATCH/DELETE, пока
+// режим включён. Ручка управления режимом не блокируется, иначе его нельзя
+// было бы выключить.
+func (m *Maintenance) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.Enabled() && isMutating(r.Method) && r.URL.Path != "/api/admin/maintenance" {
+			w.Header().Set("Retry-After", "300")
+			writeJSONError(w, http.StatusServiceUnavailable, "service is under maintenance")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
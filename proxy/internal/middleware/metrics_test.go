@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/metrics"
+)
+
+func TestHTTPMetricsMiddleware_ClientLabel(t *testing.T) {
+	handler := HTTPMetricsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	cases := []struct {
+		ua     string
+		client string
+	}{
+		{"curl/8.4.0", "api"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0", "browser"},
+	}
+
+	for _, tc := range cases {
+		counter := metrics.HTTPRequestsTotal.WithLabelValues("GET", "/ua-test", "200", tc.client)
+		before := testutil.ToFloat64(counter)
+
+		req := httptest.NewRequest(http.MethodGet, "/ua-test", nil)
+		req.Header.Set("User-Agent", tc.ua)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if got := testutil.ToFloat64(counter); got != before+1 {
+			t.Errorf("ua %q: counter with client=%q = %v, want %v", tc.ua, tc.client, got, before+1)
+		}
+	}
+}
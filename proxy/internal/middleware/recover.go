@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// Recover перехватывает панику обработчика, логирует её со стеком и id
+// запроса и отвечает единым JSON-телом ошибки вместо пустого 500.
+func Recover(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+
+				requestID := chimiddleware.GetReqID(r.Context())
+				logger.Error("panic recovered",
+					"panic", rec,
+					"request_id", requestID,
+					"method", r.Method,
+					"path", r.URL.Path,
+					"stack", string(debug.Stack()),
+				)
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				_ = json.NewEncoder(w).Encode(map[string]string{
+					"error":      "internal server error",
+					"request_id": requestID,
+				})
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
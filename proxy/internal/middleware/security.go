@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// SecurityHeaders добавляет базовые защитные заголовки ко всем ответам.
+// HSTS включается флагом отдельно: заголовок имеет смысл только когда сервис
+// доступен по TLS, иначе браузер закэширует неработающую политику.
+func SecurityHeaders(hsts bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("X-Content-Type-Options", "nosniff")
+			h.Set("X-Frame-Options", "DENY")
+			h.Set("Referrer-Policy", "no-referrer")
+			if hsts {
+				h.Set("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
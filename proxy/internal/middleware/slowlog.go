@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// SlowRequestLog пишет предупреждение в журнал для запросов, обработка
+// которых заняла дольше threshold. Нулевой порог отключает проверку.
+func SlowRequestLog(logger *slog.Logger, threshold time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r)
+
+			duration := time.Since(start)
+			if threshold > 0 && duration > threshold {
+				logger.Warn("slow http request",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"status", ww.Status(),
+					"duration", duration.String(),
+					"threshold", threshold.String(),
+				)
+			}
+		})
+	}
+}
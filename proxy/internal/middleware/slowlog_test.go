@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlowRequestLog_WarnsOnSlowHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	handler := SlowRequestLog(logger, 10*time.Millisecond)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(30 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+
+	out := buf.String()
+	if !strings.Contains(out, "slow http request") {
+		t.Fatalf("expected slow request warning, got: %s", out)
+	}
+	for _, field := range []string{"/api/users", "GET", "status=200"} {
+		if !strings.Contains(out, field) {
+			t.Errorf("warning missing %q: %s", field, out)
+		}
+	}
+}
+
+func TestSlowRequestLog_SilentOnFastHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	handler := SlowRequestLog(logger, time.Second)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	if buf.Len() != 0 {
+		t.Errorf("fast request must not be logged, got: %s", buf.String())
+	}
+}
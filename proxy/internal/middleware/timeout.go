@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// RouteTimeout назначает отдельный бюджет времени конкретному маршруту
+// поверх общего RequestTimeout. Дедлайн контекста — более ранний из двух,
+// поэтому бюджет можно только сузить; расширить сверх глобального нельзя —
+// для долгих маршрутов поднимайте REQUEST_TIMEOUT и ужимайте остальные.
+func RouteTimeout(timeout time.Duration, next http.HandlerFunc) http.HandlerFunc {
+	return RequestTimeout(timeout)(next).ServeHTTP
+}
+
+// RequestTimeout ограничивает суммарное время обработки запроса независимо
+// от таймаутов чтения и записи сервера. По истечении timeout контекст
+// запроса отменяется — обработчики и нижележащие вызовы (база, гео)
+// должны его учитывать. Если к этому моменту ответ ещё не начат, клиент
+// получает 503. timeout <= 0 отключает ограничение.
+func RequestTimeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			if ctx.Err() == context.DeadlineExceeded && ww.Status() == 0 && ww.BytesWritten() == 0 {
+				writeJSONError(ww, http.StatusServiceUnavailable, "request timed out")
+			}
+		})
+	}
+}
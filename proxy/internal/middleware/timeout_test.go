@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestTimeout_SlowHandlerGets503(t *testing.T) {
+	handler := RequestTimeout(20 * time.Millisecond)(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			// Обработчик, уважающий контекст: ждёт отмены и выходит,
+			// ничего не записав.
+			<-r.Context().Done()
+		}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("got %d, want 503 when the handler exceeds the timeout", rec.Code)
+	}
+}
+
+func TestRequestTimeout_FastHandlerUnaffected(t *testing.T) {
+	handler := RequestTimeout(time.Second)(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("got %d, want 200", rec.Code)
+	}
+}
+
+func TestRequestTimeout_StartedResponseNotOverwritten(t *testing.T) {
+	handler := RequestTimeout(20 * time.Millisecond)(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			<-r.Context().Done()
+		}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("got %d, want 200: a started response must not be overwritten", rec.Code)
+	}
+}
+
+func TestRequestTimeout_ZeroDisables(t *testing.T) {
+	handler := RequestTimeout(0)(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := r.Context().Deadline(); ok {
+				t.Error("zero timeout must not set a deadline")
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("got %d, want 200", rec.Code)
+	}
+}
+
+func TestRouteTimeout_ShortOverrideTimesOut(t *testing.T) {
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}
+	// Глобальный бюджет щедрый, у маршрута — короткий собственный.
+	handler := RequestTimeout(time.Second)(RouteTimeout(20*time.Millisecond, slow))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("got %d, want 503 from the per-route budget", rec.Code)
+	}
+}
+
+func TestRouteTimeout_DefaultBudgetRouteUnaffected(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(40 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}
+	handler := RequestTimeout(time.Second)(http.HandlerFunc(ok))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("got %d, want 200 within the default budget", rec.Code)
+	}
+}
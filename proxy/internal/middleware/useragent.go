@@ -0,0 +1,33 @@
+package middleware
+
+import "strings"
+
+// ClassifyUserAgent относит User-Agent к небольшому фиксированному набору
+// классов, чтобы не раздувать кардинальность метрик: "browser", "mobile",
+// "api", "bot" или "unknown".
+func ClassifyUserAgent(ua string) string {
+	if ua == "" {
+		return "unknown"
+	}
+	lower := strings.ToLower(ua)
+
+	for _, marker := range []string{"bot", "crawler", "spider", "slurp"} {
+		if strings.Contains(lower, marker) {
+			return "bot"
+		}
+	}
+	for _, marker := range []string{"curl", "wget", "go-http-client", "python-requests", "okhttp", "postman", "insomnia"} {
+		if strings.Contains(lower, marker) {
+			return "api"
+		}
+	}
+	for _, marker := range []string{"mobile", "android", "iphone", "ipad"} {
+		if strings.Contains(lower, marker) {
+			return "mobile"
+		}
+	}
+	if strings.Contains(lower, "mozilla") {
+		return "browser"
+	}
+	return "unknown"
+}
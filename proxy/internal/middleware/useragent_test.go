@@ -0,0 +1,27 @@
+package middleware
+
+import "testing"
+
+func TestClassifyUserAgent(t *testing.T) {
+	tests := []struct {
+		ua   string
+		want string
+	}{
+		{"", "unknown"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36", "browser"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Mobile/15E148", "mobile"},
+		{"Mozilla/5.0 (Linux; Android 14; Pixel 8) Mobile Safari/537.36", "mobile"},
+		{"curl/8.4.0", "api"},
+		{"Go-http-client/1.1", "api"},
+		{"python-requests/2.31.0", "api"},
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", "bot"},
+		{"YandexBot/3.0", "bot"},
+		{"SomethingNobodyKnows/0.1", "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := ClassifyUserAgent(tt.ua); got != tt.want {
+			t.Errorf("ClassifyUserAgent(%q) = %q, want %q", tt.ua, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN deleted_reason TEXT;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN deleted_reason;
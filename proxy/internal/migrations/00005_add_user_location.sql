@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN location_city TEXT;
+ALTER TABLE users ADD COLUMN location_street TEXT;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN location_street;
+ALTER TABLE users DROP COLUMN location_city;
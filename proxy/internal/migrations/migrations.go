@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"log/slog"
+
+	"github.com/pressly/goose/v3"
+)
+
+//go:embed *.sql
+var embedMigrations embed.FS
+
+// migrationLockID — идентификатор advisory-блокировки миграций, общий для
+// всех реплик сервиса. Произвольное число, лишь бы не пересекалось с
+// другими блокировками в той же базе.
+const migrationLockID = 48151623
+
+// withAdvisoryLock выполняет fn под advisory-блокировкой Postgres: при
+// одновременном старте нескольких реплик миграции применяет одна, остальные
+// ждут освобождения блокировки и проходят по уже применённой схеме.
+func withAdvisoryLock(db *sql.DB, fn func() error) error {
+	ctx := context.Background()
+
+	// Блокировка привязана к сессии, поэтому берём выделенное соединение:
+	// соединение из пула могло бы отпустить её не там, где взяло.
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection for migration lock: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		_, _ = conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLockID)
+	}()
+
+	return fn()
+}
+
+// RunIfEnabled применяет миграции, если они включены конфигурацией.
+// Выключенные миграции (например, на реплике, которой менять схему нельзя)
+// пропускаются с записью в журнал, не трогая goose и базу.
+func RunIfEnabled(db *sql.DB, enabled bool, logger *slog.Logger) error {
+	if !enabled {
+		if logger == nil {
+			logger = slog.Default()
+		}
+		logger.Info("migrations skipped: disabled by RUN_MIGRATIONS")
+		return nil
+	}
+	return RunMigrations(db)
+}
+
+// RunMigrations применяет все миграции к базе. Запуск безопасен при
+// нескольких одновременно стартующих репликах.
+func RunMigrations(db *sql.DB) error {
+	return withAdvisoryLock(db, func() error {
+		goose.SetBaseFS(embedMigrations)
+		if err := goose.SetDialect("postgres"); err != nil {
+			return err
+		}
+		return goose.Up(db, ".")
+	})
+}
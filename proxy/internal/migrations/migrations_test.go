@@ -0,0 +1,83 @@
+package migrations
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestWithAdvisoryLock_WrapsCallback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("SELECT pg_advisory_lock").
+		WithArgs(int64(migrationLockID)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SELECT pg_advisory_unlock").
+		WithArgs(int64(migrationLockID)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	called := false
+	if err := withAdvisoryLock(db, func() error {
+		called = true
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !called {
+		t.Error("callback must run under the lock")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("lock/unlock not issued: %v", err)
+	}
+}
+
+func TestWithAdvisoryLock_LockFailureSkipsCallback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("SELECT pg_advisory_lock").
+		WithArgs(int64(migrationLockID)).
+		WillReturnError(errors.New("connection refused"))
+
+	if err := withAdvisoryLock(db, func() error {
+		t.Error("callback must not run when the lock is unavailable")
+		return nil
+	}); err == nil {
+		t.Fatal("expected error when lock acquisition fails")
+	}
+}
+
+func TestRunIfEnabled_DisabledSkipsDatabase(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	// Никаких ожиданий не задано: любой запрос к базе провалил бы тест.
+	if err := RunIfEnabled(db, false, logger); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("database was touched with migrations disabled: %v", err)
+	}
+	if !strings.Contains(buf.String(), "migrations skipped") {
+		t.Errorf("skip must be logged, got %q", buf.String())
+	}
+}
@@ -0,0 +1,478 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/entity"
+)
+
+var (
+	// ErrNotFound возвращается, когда пользователь не найден или удалён.
+	ErrNotFound = errors.New("user not found")
+	// ErrAlreadyExists возвращается при попытке создать пользователя с занятым email.
+	ErrAlreadyExists = errors.New("user already exists")
+)
+
+// UserRepository — доступ к таблице users.
+type UserRepository interface {
+	Create(ctx context.Context, user *entity.User) error
+	GetByID(ctx context.Context, id int) (*entity.User, error)
+	GetByEmail(ctx context.Context, email string) (*entity.User, error)
+	Update(ctx context.Context, user *entity.User) error
+	Delete(ctx context.Context, id int, reason string) error
+	DeleteBatch(ctx context.Context, ids []int) ([]int, error)
+	List(ctx context.Context, limit, offset int) ([]*entity.User, error)
+	ListAfter(ctx context.Context, afterID, limit int) ([]*entity.User, error)
+	ListAll(ctx context.Context, limit, offset int) ([]*entity.User, error)
+	ListCreatedBetween(ctx context.Context, from, to time.Time, limit, offset int) ([]*entity.User, error)
+	SetLocation(ctx context.Context, id int, city, street string) error
+	SetPendingEmail(ctx context.Context, id int, email, token string) error
+	CommitPendingEmail(ctx context.Context, id int, token string) error
+	// WithTx выполняет fn против репозитория, все запросы которого идут
+	// в одной транзакции: ошибка fn откатывает её, успех — фиксирует.
+	WithTx(ctx context.Context, fn func(UserRepository) error) error
+}
+
+// queryer — операции, общие для *sqlx.DB и *sqlx.Tx. Методы репозитория
+// выполняются через него и потому одинаково работают и на прямом
+// подключении, и внутри транзакции.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// stmtCache — кэш подготовленных именованных запросов: горячие запросы
+// разбираются базой один раз, а не на каждом вызове. Безопасен для
+// конкурентного использования.
+type stmtCache struct {
+	mu    sync.Mutex
+	stmts map[string]*sqlx.NamedStmt
+}
+
+// get возвращает подготовленный запрос из кэша, готовя его при первом
+// обращении.
+func (c *stmtCache) get(ctx context.Context, db *sqlx.DB, query string) (*sqlx.NamedStmt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if stmt, ok := c.stmts[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := db.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("prepare statement: %w", err)
+	}
+	c.stmts[query] = stmt
+	return stmt, nil
+}
+
+type userRepository struct {
+	db *sqlx.DB
+	// q — текущий исполнитель запросов: само подключение или транзакция,
+	// открытая в WithTx.
+	q queryer
+	// stmts — общий для всех копий репозитория кэш подготовленных запросов.
+	stmts *stmtCache
+	// queryTimeout ограничивает длительность одного запроса к базе.
+	queryTimeout time.Duration
+	// slowQuery — порог, после которого запрос попадает в журнал как медленный.
+	slowQuery time.Duration
+	logger    *slog.Logger
+}
+
+// NewUserRepository создаёт репозиторий пользователей поверх подключения к базе.
+func NewUserRepository(db *sqlx.DB, queryTimeout, slowQuery time.Duration, logger *slog.Logger) UserRepository {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &userRepository{
+		db:           db,
+		q:            db,
+		stmts:        &stmtCache{stmts: make(map[string]*sqlx.NamedStmt)},
+		queryTimeout: queryTimeout,
+		slowQuery:    slowQuery,
+		logger:       logger,
+	}
+}
+
+// namedQueryRow выполняет именованный запрос, возвращающий одну строку.
+// Вне транзакции запрос идёт через кэш подготовленных запросов; внутри —
+// разбирается на месте, потому что подготовленный на подключении запрос
+// выполнился бы вне транзакции.
+func (r *userRepository) namedQueryRow(ctx context.Context, query string, arg interface{}) (*sqlx.Row, error) {
+	if tx, ok := r.q.(*sqlx.Tx); ok {
+		q, args, err := tx.BindNamed(query, arg)
+		if err != nil {
+			return nil, err
+		}
+		return tx.QueryRowxContext(ctx, q, args...), nil
+	}
+	stmt, err := r.stmts.get(ctx, r.db, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.QueryRowxContext(ctx, arg), nil
+}
+
+// namedExec выполняет именованный запрос без результата — с тем же
+// разделением путей, что и namedQueryRow.
+func (r *userRepository) namedExec(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	if tx, ok := r.q.(*sqlx.Tx); ok {
+		return tx.NamedExecContext(ctx, query, arg)
+	}
+	stmt, err := r.stmts.get(ctx, r.db, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.ExecContext(ctx, arg)
+}
+
+// WithTx открывает транзакцию и выполняет fn против её репозитория.
+// Ошибка fn откатывает транзакцию и возвращается как есть; успешное
+// завершение фиксирует её. Вложенный WithTx откроет отдельную транзакцию.
+func (r *userRepository) WithTx(ctx context.Context, fn func(UserRepository) error) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	txRepo := *r
+	txRepo.q = tx
+	if err := fn(&txRepo); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// withTimeout ограничивает контекст запроса таймаутом репозитория.
+func (r *userRepository) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, r.queryTimeout)
+}
+
+// logSlow пишет предупреждение, если запрос шёл дольше порога slowQuery.
+// Вызывается через defer с моментом старта запроса.
+func (r *userRepository) logSlow(name string, start time.Time) {
+	if r.slowQuery <= 0 {
+		return
+	}
+	if elapsed := time.Since(start); elapsed > r.slowQuery {
+		r.logger.Warn("slow query",
+			"query", name,
+			"duration", elapsed.String(),
+			"threshold", r.slowQuery.String(),
+		)
+	}
+}
+
+// wrapTimeout оборачивает ошибку превышения таймаута запроса. Драйвер может
+// вернуть собственную ошибку отмены, поэтому дополнительно смотрим на контекст.
+func (r *userRepository) wrapTimeout(ctx context.Context, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("db query timed out after %s: %w", r.queryTimeout, context.DeadlineExceeded)
+	}
+	return err
+}
+
+func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
+	if err := user.Validate(); err != nil {
+		return err
+	}
+
+	const query = `
+		INSERT INTO users (email, password_hash, created_at, updated_at)
+		VALUES (:email, :password_hash, :created_at, :created_at)
+		RETURNING id`
+
+	now := time.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	defer r.logSlow("users.Create", time.Now())
+
+	row, err := r.namedQueryRow(ctx, query, map[string]interface{}{
+		"email":         user.Email,
+		"password_hash": user.PasswordHash,
+		"created_at":    now,
+	})
+	if err == nil {
+		err = row.Scan(&user.ID)
+	}
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrAlreadyExists
+		}
+		return r.wrapTimeout(ctx, err)
+	}
+	return nil
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id int) (*entity.User, error) {
+	const query = `SELECT * FROM users WHERE id = $1 AND deleted_at IS NULL`
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	defer r.logSlow("users.GetByID", time.Now())
+
+	var user entity.User
+	if err := r.q.GetContext(ctx, &user, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, r.wrapTimeout(ctx, err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	const query = `SELECT * FROM users WHERE email = $1 AND deleted_at IS NULL`
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	defer r.logSlow("users.GetByEmail", time.Now())
+
+	var user entity.User
+	if err := r.q.GetContext(ctx, &user, query, email); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, r.wrapTimeout(ctx, err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) Update(ctx context.Context, user *entity.User) error {
+	if err := user.Validate(); err != nil {
+		return err
+	}
+
+	const query = `
+		UPDATE users
+		SET email = :email, password_hash = :password_hash, updated_at = :updated_at
+		WHERE id = :id AND deleted_at IS NULL`
+
+	user.UpdatedAt = time.Now()
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	defer r.logSlow("users.Update", time.Now())
+
+	res, err := r.namedExec(ctx, query, map[string]interface{}{
+		"email":         user.Email,
+		"password_hash": user.PasswordHash,
+		"updated_at":    user.UpdatedAt,
+		"id":            user.ID,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrAlreadyExists
+		}
+		return r.wrapTimeout(ctx, err)
+	}
+	return checkAffected(res)
+}
+
+func (r *userRepository) SetLocation(ctx context.Context, id int, city, street string) error {
+	const query = `UPDATE users SET location_city = NULLIF($1, ''), location_street = NULLIF($2, '')
+		WHERE id = $3 AND deleted_at IS NULL`
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	defer r.logSlow("users.SetLocation", time.Now())
+
+	res, err := r.q.ExecContext(ctx, query, city, street, id)
+	if err != nil {
+		return r.wrapTimeout(ctx, err)
+	}
+	return checkAffected(res)
+}
+
+func (r *userRepository) Delete(ctx context.Context, id int, reason string) error {
+	const query = `UPDATE users SET deleted_at = $1, deleted_reason = NULLIF($2, '')
+		WHERE id = $3 AND deleted_at IS NULL`
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	defer r.logSlow("users.Delete", time.Now())
+
+	res, err := r.q.ExecContext(ctx, query, time.Now(), reason, id)
+	if err != nil {
+		return r.wrapTimeout(ctx, err)
+	}
+	return checkAffected(res)
+}
+
+// DeleteBatch помечает пользователей удалёнными одним атомарным запросом и
+// возвращает id фактически удалённых; несуществующие и уже удалённые
+// просто не попадают в результат.
+func (r *userRepository) DeleteBatch(ctx context.Context, ids []int) ([]int, error) {
+	const query = `
+		UPDATE users SET deleted_at = $1
+		WHERE id = ANY($2) AND deleted_at IS NULL
+		RETURNING id`
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	defer r.logSlow("users.DeleteBatch", time.Now())
+
+	deleted := make([]int, 0, len(ids))
+	if err := r.q.SelectContext(ctx, &deleted, query, time.Now(), pq.Array(ids)); err != nil {
+		return nil, r.wrapTimeout(ctx, err)
+	}
+	return deleted, nil
+}
+
+func (r *userRepository) List(ctx context.Context, limit, offset int) ([]*entity.User, error) {
+	const query = `
+		SELECT * FROM users
+		WHERE deleted_at IS NULL
+		ORDER BY id
+		LIMIT $1 OFFSET $2`
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	defer r.logSlow("users.List", time.Now())
+
+	users := make([]*entity.User, 0)
+	if err := r.q.SelectContext(ctx, &users, query, limit, offset); err != nil {
+		return nil, r.wrapTimeout(ctx, err)
+	}
+	return users, nil
+}
+
+// ListAll возвращает страницу пользователей, включая помеченных удалёнными.
+func (r *userRepository) ListAll(ctx context.Context, limit, offset int) ([]*entity.User, error) {
+	const query = `
+		SELECT * FROM users
+		ORDER BY id
+		LIMIT $1 OFFSET $2`
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	defer r.logSlow("users.ListAll", time.Now())
+
+	users := make([]*entity.User, 0)
+	if err := r.q.SelectContext(ctx, &users, query, limit, offset); err != nil {
+		return nil, r.wrapTimeout(ctx, err)
+	}
+	return users, nil
+}
+
+// ListCreatedBetween возвращает страницу пользователей, созданных в
+// интервале [from, to): from включительно, to — нет.
+func (r *userRepository) ListCreatedBetween(ctx context.Context, from, to time.Time, limit, offset int) ([]*entity.User, error) {
+	const query = `
+		SELECT * FROM users
+		WHERE deleted_at IS NULL AND created_at >= $1 AND created_at < $2
+		ORDER BY id
+		LIMIT $3 OFFSET $4`
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	defer r.logSlow("users.ListCreatedBetween", time.Now())
+
+	users := make([]*entity.User, 0)
+	if err := r.q.SelectContext(ctx, &users, query, from, to, limit, offset); err != nil {
+		return nil, r.wrapTimeout(ctx, err)
+	}
+	return users, nil
+}
+
+// ListAfter возвращает страницу пользователей с id больше afterID —
+// курсорная пагинация, устойчивая к вставкам между запросами.
+func (r *userRepository) ListAfter(ctx context.Context, afterID, limit int) ([]*entity.User, error) {
+	const query = `
+		SELECT * FROM users
+		WHERE deleted_at IS NULL AND id > $1
+		ORDER BY id
+		LIMIT $2`
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	defer r.logSlow("users.ListAfter", time.Now())
+
+	users := make([]*entity.User, 0)
+	if err := r.q.SelectContext(ctx, &users, query, afterID, limit); err != nil {
+		return nil, r.wrapTimeout(ctx, err)
+	}
+	return users, nil
+}
+
+// SetPendingEmail сохраняет новый email и токен подтверждения.
+// Смена вступает в силу только после CommitPendingEmail.
+func (r *userRepository) SetPendingEmail(ctx context.Context, id int, email, token string) error {
+	const query = `
+		UPDATE users
+		SET pending_email = $1, pending_email_token = $2, updated_at = $3
+		WHERE id = $4 AND deleted_at IS NULL`
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	defer r.logSlow("users.SetPendingEmail", time.Now())
+
+	res, err := r.q.ExecContext(ctx, query, email, token, time.Now(), id)
+	if err != nil {
+		return r.wrapTimeout(ctx, err)
+	}
+	return checkAffected(res)
+}
+
+// CommitPendingEmail применяет отложенную смену email при совпадении токена.
+// Возвращает ErrNotFound при неверном токене и ErrAlreadyExists, если новый
+// email успел занять другой пользователь.
+func (r *userRepository) CommitPendingEmail(ctx context.Context, id int, token string) error {
+	const query = `
+		UPDATE users
+		SET email = pending_email, pending_email = NULL, pending_email_token = NULL, updated_at = $1
+		WHERE id = $2 AND pending_email_token = $3
+		  AND pending_email IS NOT NULL AND deleted_at IS NULL`
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	defer r.logSlow("users.CommitPendingEmail", time.Now())
+
+	res, err := r.q.ExecContext(ctx, query, time.Now(), id, token)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrAlreadyExists
+		}
+		return r.wrapTimeout(ctx, err)
+	}
+	return checkAffected(res)
+}
+
+func checkAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// isUniqueViolation распознаёт нарушение уникального индекса (код 23505 в Postgres).
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+	return false
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/entity"
+)
+
+func newMockRepo(t *testing.T, queryTimeout time.Duration) (UserRepository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { mockDB.Close() })
+
+	return NewUserRepository(sqlx.NewDb(mockDB, "sqlmock"), queryTimeout, 0, nil), mock
+}
+
+func TestUserRepository_QueryTimeout(t *testing.T) {
+	repo, mock := newMockRepo(t, time.Millisecond)
+
+	mock.ExpectQuery("SELECT \\* FROM users").
+		WillDelayFor(200 * time.Millisecond).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email"}))
+
+	_, err := repo.GetByID(context.Background(), 1)
+	if err == nil {
+		t.Fatal("expected error from slow query")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want wrapped context.DeadlineExceeded", err)
+	}
+}
+
+func TestUserRepository_CreateRejectsInvalidEntity(t *testing.T) {
+	// Ожиданий на mock нет: невалидная сущность не должна дойти до базы.
+	repo, mock := newMockRepo(t, time.Second)
+
+	err := repo.Create(context.Background(), &entity.User{PasswordHash: "hash"})
+
+	var verr *entity.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("got %v, want ValidationError", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unexpected DB interaction: %v", err)
+	}
+}
+
+func TestUserRepository_UpdateRejectsInvalidEntity(t *testing.T) {
+	repo, mock := newMockRepo(t, time.Second)
+
+	err := repo.Update(context.Background(), &entity.User{ID: 1, Email: "user@example.com"})
+
+	var verr *entity.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("got %v, want ValidationError", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unexpected DB interaction: %v", err)
+	}
+}
+
+func TestUserRepository_SlowQueryLogged(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { mockDB.Close() })
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	repo := NewUserRepository(sqlx.NewDb(mockDB, "sqlmock"), time.Second, time.Millisecond, logger)
+
+	rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "created_at", "updated_at", "deleted_at"}).
+		AddRow(1, "test@example.com", "hash", time.Now(), time.Now(), nil)
+	mock.ExpectQuery("SELECT \\* FROM users").
+		WillDelayFor(20 * time.Millisecond).
+		WillReturnRows(rows)
+
+	if _, err := repo.GetByID(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "slow query") {
+		t.Fatalf("expected slow query warning, got log: %q", out)
+	}
+	if !strings.Contains(out, "users.GetByID") {
+		t.Errorf("expected query name in log, got: %q", out)
+	}
+}
+
+func TestUserRepository_FastQueryNotLogged(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { mockDB.Close() })
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	repo := NewUserRepository(sqlx.NewDb(mockDB, "sqlmock"), time.Second, time.Second, logger)
+
+	rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "created_at", "updated_at", "deleted_at"}).
+		AddRow(1, "test@example.com", "hash", time.Now(), time.Now(), nil)
+	mock.ExpectQuery("SELECT \\* FROM users").WillReturnRows(rows)
+
+	if _, err := repo.GetByID(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no log output for fast query, got: %q", buf.String())
+	}
+}
+
+func TestUserRepository_CreatePreparesStatementOnce(t *testing.T) {
+	repo, mock := newMockRepo(t, time.Second)
+
+	// Одна подготовка на два вызова: повторный Create берёт запрос из кэша.
+	mock.ExpectPrepare("INSERT INTO users")
+	mock.ExpectQuery("INSERT INTO users").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("INSERT INTO users").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+
+	first := &entity.User{Email: "first@example.com", PasswordHash: "hash"}
+	if err := repo.Create(context.Background(), first); err != nil {
+		t.Fatalf("first create: %v", err)
+	}
+	second := &entity.User{Email: "second@example.com", PasswordHash: "hash"}
+	if err := repo.Create(context.Background(), second); err != nil {
+		t.Fatalf("second create: %v", err)
+	}
+
+	if first.ID != 1 || second.ID != 2 {
+		t.Errorf("got ids %d and %d, want 1 and 2", first.ID, second.ID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("statement was prepared more than once: %v", err)
+	}
+}
+
+func BenchmarkUserRepository_Create(b *testing.B) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		b.Fatalf("create sqlmock: %v", err)
+	}
+	b.Cleanup(func() { mockDB.Close() })
+
+	repo := NewUserRepository(sqlx.NewDb(mockDB, "sqlmock"), time.Second, 0, nil)
+
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectPrepare("INSERT INTO users")
+	for i := 0; i < b.N; i++ {
+		mock.ExpectQuery("INSERT INTO users").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	}
+
+	user := &entity.User{Email: "bench@example.com", PasswordHash: "hash"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := repo.Create(context.Background(), user); err != nil {
+			b.Fatalf("create: %v", err)
+		}
+	}
+}
+
+func TestUserRepository_WithTxRollsBackOnCallbackError(t *testing.T) {
+	repo, mock := newMockRepo(t, time.Second)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO users").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectRollback()
+
+	wantErr := errors.New("audit failed")
+	err := repo.WithTx(context.Background(), func(tx UserRepository) error {
+		if err := tx.Create(context.Background(), &entity.User{
+			Email:        "tx@example.com",
+			PasswordHash: "hash",
+		}); err != nil {
+			return err
+		}
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("got %v, want callback error", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("transaction was not rolled back: %v", err)
+	}
+}
+
+func TestUserRepository_WithTxCommitsOnSuccess(t *testing.T) {
+	repo, mock := newMockRepo(t, time.Second)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO users").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	err := repo.WithTx(context.Background(), func(tx UserRepository) error {
+		return tx.Create(context.Background(), &entity.User{
+			Email:        "tx@example.com",
+			PasswordHash: "hash",
+		})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("transaction was not committed: %v", err)
+	}
+}
+
+func TestUserRepository_FastQueryWithinTimeout(t *testing.T) {
+	repo, mock := newMockRepo(t, time.Second)
+
+	rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "created_at", "updated_at", "deleted_at"}).
+		AddRow(1, "test@example.com", "hash", time.Now(), time.Now(), nil)
+	mock.ExpectQuery("SELECT \\* FROM users").WillReturnRows(rows)
+
+	user, err := repo.GetByID(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.Email != "test@example.com" {
+		t.Errorf("got email %q, want test@example.com", user.Email)
+	}
+}
@@ -0,0 +1,96 @@
+package responder
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/metrics"
+)
+
+// ErrorResponse — единый формат тела ошибки API.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// JSONResponder пишет ответы API в формате JSON.
+type JSONResponder struct {
+	logger *slog.Logger
+	pretty bool
+}
+
+// NewJSONResponder создаёт responder с переданным логгером.
+func NewJSONResponder(logger *slog.Logger) *JSONResponder {
+	return &JSONResponder{logger: logger}
+}
+
+// WithPretty включает форматирование ответов с отступами — удобно при
+// отладке через curl. По умолчанию вывод компактный.
+func (r *JSONResponder) WithPretty(enabled bool) *JSONResponder {
+	r.pretty = enabled
+	return r
+}
+
+// Respond сериализует data в JSON и пишет его с указанным статусом.
+func (r *JSONResponder) Respond(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if data == nil {
+		return
+	}
+	enc := json.NewEncoder(w)
+	if r.pretty {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(data); err != nil {
+		r.logger.Error("encode response", "error", err)
+	}
+}
+
+// RespondStream пишет элементы канала одним JSON-массивом, сбрасывая буфер
+// после каждого элемента: большие выборки уходят клиенту по мере чтения и
+// не буферизуются в памяти целиком. Канал должен быть закрыт отправителем.
+func (r *JSONResponder) RespondStream(w http.ResponseWriter, status int, items <-chan interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	flusher, _ := w.(http.Flusher)
+	if _, err := io.WriteString(w, "["); err != nil {
+		r.logger.Error("stream response", "error", err)
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	first := true
+	for item := range items {
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				r.logger.Error("stream response", "error", err)
+				return
+			}
+		}
+		first = false
+		if err := enc.Encode(item); err != nil {
+			r.logger.Error("encode stream element", "error", err)
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	if _, err := io.WriteString(w, "]"); err != nil {
+		r.logger.Error("stream response", "error", err)
+	}
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+// Error пишет тело ошибки в формате {"error": ...} и учитывает её в метриках.
+func (r *JSONResponder) Error(w http.ResponseWriter, status int, msg string) {
+	metrics.APIErrorsTotal.WithLabelValues(strconv.Itoa(status)).Inc()
+	r.Respond(w, status, ErrorResponse{Error: msg})
+}
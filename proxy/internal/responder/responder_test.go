@@ -0,0 +1,107 @@
+package responder
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/metrics"
+)
+
+func TestError_CountsByStatus(t *testing.T) {
+	r := NewJSONResponder(slog.New(slog.NewTextHandler(httptest.NewRecorder(), nil)))
+
+	before400 := testutil.ToFloat64(metrics.APIErrorsTotal.WithLabelValues("400"))
+	before500 := testutil.ToFloat64(metrics.APIErrorsTotal.WithLabelValues("500"))
+
+	r.Error(httptest.NewRecorder(), http.StatusBadRequest, "bad request")
+	r.Error(httptest.NewRecorder(), http.StatusBadRequest, "bad request")
+	r.Error(httptest.NewRecorder(), http.StatusInternalServerError, "boom")
+
+	if got := testutil.ToFloat64(metrics.APIErrorsTotal.WithLabelValues("400")); got != before400+2 {
+		t.Errorf("400 counter = %v, want %v", got, before400+2)
+	}
+	if got := testutil.ToFloat64(metrics.APIErrorsTotal.WithLabelValues("500")); got != before500+1 {
+		t.Errorf("500 counter = %v, want %v", got, before500+1)
+	}
+}
+
+func TestRespondStream_ValidJSONArray(t *testing.T) {
+	r := NewJSONResponder(slog.New(slog.NewTextHandler(httptest.NewRecorder(), nil)))
+
+	items := make(chan interface{})
+	go func() {
+		defer close(items)
+		for i := 1; i <= 3; i++ {
+			items <- map[string]int{"id": i}
+		}
+	}()
+
+	rec := httptest.NewRecorder()
+	r.RespondStream(rec, http.StatusOK, items)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got Content-Type %q, want application/json", ct)
+	}
+
+	var out []struct {
+		ID int `json:"id"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatalf("stream output is not valid JSON: %v (body: %s)", err, rec.Body.String())
+	}
+	if len(out) != 3 || out[0].ID != 1 || out[2].ID != 3 {
+		t.Errorf("unexpected elements: %+v", out)
+	}
+}
+
+func TestRespondStream_EmptyChannelIsEmptyArray(t *testing.T) {
+	r := NewJSONResponder(slog.New(slog.NewTextHandler(httptest.NewRecorder(), nil)))
+
+	items := make(chan interface{})
+	close(items)
+
+	rec := httptest.NewRecorder()
+	r.RespondStream(rec, http.StatusOK, items)
+
+	var out []interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatalf("stream output is not valid JSON: %v (body: %s)", err, rec.Body.String())
+	}
+	if len(out) != 0 {
+		t.Errorf("got %d elements, want empty array", len(out))
+	}
+}
+
+func TestRespond_PrettyIndentsOutput(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(httptest.NewRecorder(), nil))
+	payload := map[string]string{"key": "value"}
+
+	compact := httptest.NewRecorder()
+	NewJSONResponder(logger).Respond(compact, http.StatusOK, payload)
+	if strings.Contains(compact.Body.String(), "\n  ") {
+		t.Errorf("default output must be compact, got %q", compact.Body.String())
+	}
+
+	pretty := httptest.NewRecorder()
+	NewJSONResponder(logger).WithPretty(true).Respond(pretty, http.StatusOK, payload)
+	if !strings.Contains(pretty.Body.String(), "\n  ") {
+		t.Errorf("pretty output must be indented, got %q", pretty.Body.String())
+	}
+
+	var out map[string]string
+	if err := json.Unmarshal(pretty.Body.Bytes(), &out); err != nil {
+		t.Fatalf("pretty output is not valid JSON: %v", err)
+	}
+	if out["key"] != "value" {
+		t.Errorf("unexpected payload: %+v", out)
+	}
+}
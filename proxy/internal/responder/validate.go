@@ -0,0 +1,86 @@
+package responder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validate — общий валидатор DTO. Имена полей в ошибках берутся из json-тегов.
+var validate = func() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}()
+
+// FieldError — ошибка валидации одного поля запроса.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse — тело ответа 400 с построчными ошибками валидации.
+type ValidationErrorResponse struct {
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields"`
+}
+
+// DecodeAndValidate декодирует тело запроса в dst и проверяет его по
+// validate-тегам. При ошибке пишет ответ 400 и возвращает false —
+// обработчику достаточно выйти.
+func (r *JSONResponder) DecodeAndValidate(w http.ResponseWriter, req *http.Request, dst interface{}) bool {
+	if err := json.NewDecoder(req.Body).Decode(dst); err != nil {
+		r.Error(w, http.StatusBadRequest, "invalid request body")
+		return false
+	}
+
+	if err := validate.Struct(dst); err != nil {
+		var verrs validator.ValidationErrors
+		if !errors.As(err, &verrs) {
+			r.Error(w, http.StatusBadRequest, "invalid request body")
+			return false
+		}
+
+		fields := make([]FieldError, 0, len(verrs))
+		for _, fe := range verrs {
+			fields = append(fields, FieldError{Field: fe.Field(), Message: fieldMessage(fe)})
+		}
+		r.Respond(w, http.StatusBadRequest, ValidationErrorResponse{
+			Error:  "validation failed",
+			Fields: fields,
+		})
+		return false
+	}
+	return true
+}
+
+// fieldMessage переводит ошибку валидатора в человекочитаемое сообщение.
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		return fmt.Sprintf("must be at least %s characters long", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s characters long", fe.Param())
+	case "latitude":
+		return "must be a valid latitude"
+	case "longitude":
+		return "must be a valid longitude"
+	default:
+		return fmt.Sprintf("failed %q validation", fe.Tag())
+	}
+}
@@ -0,0 +1,84 @@
+package responder
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+type validateDTO struct {
+	Email string `json:"email" validate:"required,email"`
+	Lat   string `json:"lat" validate:"required,latitude"`
+}
+
+func decodeInto(t *testing.T, body string) (*httptest.ResponseRecorder, bool) {
+	t.Helper()
+
+	rsp := NewJSONResponder(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	var dto validateDTO
+	ok := rsp.DecodeAndValidate(rec, req, &dto)
+	return rec, ok
+}
+
+func TestDecodeAndValidate_Valid(t *testing.T) {
+	rec, ok := decodeInto(t, `{"email":"user@example.com","lat":"55.7558"}`)
+	if !ok {
+		t.Fatalf("expected valid body to pass, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestDecodeAndValidate_MissingRequired(t *testing.T) {
+	rec, ok := decodeInto(t, `{"lat":"55.7558"}`)
+	if ok {
+		t.Fatal("expected missing email to fail validation")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("got %d, want 400", rec.Code)
+	}
+
+	var resp ValidationErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Fields) != 1 || resp.Fields[0].Field != "email" {
+		t.Errorf("got fields %+v, want single error for email", resp.Fields)
+	}
+	if resp.Fields[0].Message != "is required" {
+		t.Errorf("got message %q, want %q", resp.Fields[0].Message, "is required")
+	}
+}
+
+func TestDecodeAndValidate_PatternMismatch(t *testing.T) {
+	rec, ok := decodeInto(t, `{"email":"not-an-email","lat":"not-a-latitude"}`)
+	if ok {
+		t.Fatal("expected malformed values to fail validation")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("got %d, want 400", rec.Code)
+	}
+
+	var resp ValidationErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Fields) != 2 {
+		t.Fatalf("got fields %+v, want errors for email and lat", resp.Fields)
+	}
+}
+
+func TestDecodeAndValidate_MalformedJSON(t *testing.T) {
+	rec, ok := decodeInto(t, `{"email":`)
+	if ok {
+		t.Fatal("expected malformed JSON to fail")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("got %d, want 400", rec.Code)
+	}
+}
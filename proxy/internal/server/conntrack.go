@@ -0,0 +1,39 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"sync/atomic"
+)
+
+// ConnTracker считает открытые и активные соединения HTTP-сервера через
+// хук http.Server.ConnState — чтобы при остановке видеть, сколько
+// соединений пришлось дожидаться.
+type ConnTracker struct {
+	open   atomic.Int64
+	active atomic.Int64
+}
+
+// ConnState — обработчик для http.Server.ConnState.
+func (t *ConnTracker) ConnState(_ net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		t.open.Add(1)
+	case http.StateActive:
+		t.active.Add(1)
+	case http.StateIdle:
+		t.active.Add(-1)
+	case http.StateClosed, http.StateHijacked:
+		t.open.Add(-1)
+	}
+}
+
+// Open возвращает число открытых соединений.
+func (t *ConnTracker) Open() int64 {
+	return t.open.Load()
+}
+
+// Active возвращает число соединений, обрабатывающих запрос прямо сейчас.
+func (t *ConnTracker) Active() int64 {
+	return t.active.Load()
+}
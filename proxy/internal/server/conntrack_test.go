@@ -0,0 +1,40 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestConnTracker_Transitions(t *testing.T) {
+	var tracker ConnTracker
+
+	// Два соединения открылись, одно начало обрабатывать запрос.
+	tracker.ConnState(nil, http.StateNew)
+	tracker.ConnState(nil, http.StateNew)
+	tracker.ConnState(nil, http.StateActive)
+
+	if got := tracker.Open(); got != 2 {
+		t.Errorf("open = %d, want 2", got)
+	}
+	if got := tracker.Active(); got != 1 {
+		t.Errorf("active = %d, want 1", got)
+	}
+
+	// Запрос обработан, соединение перешло в idle и закрылось.
+	tracker.ConnState(nil, http.StateIdle)
+	tracker.ConnState(nil, http.StateClosed)
+
+	if got := tracker.Open(); got != 1 {
+		t.Errorf("open = %d, want 1", got)
+	}
+	if got := tracker.Active(); got != 0 {
+		t.Errorf("active = %d, want 0", got)
+	}
+
+	// Второе соединение перехвачено (hijack) — тоже перестаёт учитываться.
+	tracker.ConnState(nil, http.StateHijacked)
+
+	if got := tracker.Open(); got != 0 {
+		t.Errorf("open = %d, want 0", got)
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/cache"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/entity"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/metrics"
+)
+
+// GeoServiceProxy — кэширующая обёртка над GeoServicer.
+type GeoServiceProxy struct {
+	geo   GeoServicer
+	cache cache.Cache
+	ttl   time.Duration
+	// jitterPct — разброс TTL в процентах (±N%), разводящий истечение
+	// записей одной волны по времени.
+	jitterPct int
+	// maxKeyLen — предел длины ключа кэша; более длинные ключи
+	// заменяются хэшем.
+	maxKeyLen int
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// defaultMaxCacheKeyLen — предел длины ключа кэша по умолчанию.
+const defaultMaxCacheKeyLen = 128
+
+// NewGeoServiceProxy создаёт кэширующий прокси с заданным TTL и разбросом.
+// maxKeyLen ограничивает длину ключей кэша; 0 — предел по умолчанию.
+func NewGeoServiceProxy(geo GeoServicer, c cache.Cache, ttl time.Duration, jitterPct, maxKeyLen int) *GeoServiceProxy {
+	if maxKeyLen <= 0 {
+		maxKeyLen = defaultMaxCacheKeyLen
+	}
+	return &GeoServiceProxy{
+		geo:       geo,
+		cache:     c,
+		ttl:       ttl,
+		jitterPct: jitterPct,
+		maxKeyLen: maxKeyLen,
+		rnd:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// cacheKey собирает ключ кэша из префикса и полезной части. Короткие ключи
+// остаются человекочитаемыми; ключ длиннее maxKeyLen заменяется префиксом с
+// усечённым sha256 — длинный запрос не должен раздувать карту в памяти или
+// индекс в базе.
+func (p *GeoServiceProxy) cacheKey(prefix, payload string) string {
+	key := prefix + ":" + payload
+	if len(key) <= p.maxKeyLen {
+		return key
+	}
+	sum := sha256.Sum256([]byte(payload))
+	return prefix + ":sha256:" + hex.EncodeToString(sum[:16])
+}
+
+// entryTTL возвращает TTL записи со случайным разбросом в пределах ±jitterPct%.
+func (p *GeoServiceProxy) entryTTL() time.Duration {
+	if p.jitterPct <= 0 {
+		return p.ttl
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	span := float64(p.ttl) * float64(p.jitterPct) / 100
+	delta := (p.rnd.Float64()*2 - 1) * span
+	return p.ttl + time.Duration(delta)
+}
+
+// AddressSearch возвращает результат из кэша или запрашивает его у внешнего сервиса.
+func (p *GeoServiceProxy) AddressSearch(input string) ([]*entity.Address, error) {
+	addresses, _, err := p.AddressSearchWithSource(input)
+	return addresses, err
+}
+
+// AddressSearchWithSource — AddressSearch с источником результата: "cache"
+// для ответа из кэша, имя провайдера — для свежего.
+func (p *GeoServiceProxy) AddressSearchWithSource(input string) ([]*entity.Address, string, error) {
+	start := time.Now()
+	cacheResult := "hit"
+	source := GeoSourceCache
+
+	key := p.cacheKey("address_search", input)
+	v, err := p.cache.GetOrSet(key, p.entryTTL(), func() (interface{}, error) {
+		cacheResult = "miss"
+		addresses, s, err := AddressSearchSourced(p.geo, input)
+		source = s
+		return addresses, err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if addresses, ok := decodeAddresses(v); ok {
+		metrics.GeoRequestDuration.WithLabelValues("address_search", cacheResult).Observe(time.Since(start).Seconds())
+		return addresses, source, nil
+	}
+	// Значение в кэше нечитаемо — идём к сервису напрямую.
+	addresses, source, err := AddressSearchSourced(p.geo, input)
+	metrics.GeoRequestDuration.WithLabelValues("address_search", "miss").Observe(time.Since(start).Seconds())
+	return addresses, source, err
+}
+
+// decodeAddresses приводит закэшированное значение к срезу адресов. Кэш в
+// памяти хранит готовый срез, кэш в базе — сырой JSON, поэтому
+// поддерживаются оба представления.
+func decodeAddresses(v interface{}) ([]*entity.Address, bool) {
+	switch val := v.(type) {
+	case []*entity.Address:
+		return val, true
+	case json.RawMessage:
+		var addresses []*entity.Address
+		if err := json.Unmarshal(val, &addresses); err != nil {
+			return nil, false
+		}
+		return addresses, true
+	default:
+		return nil, false
+	}
+}
+
+// GeoCode возвращает результат из кэша или запрашивает его у внешнего сервиса.
+func (p *GeoServiceProxy) GeoCode(lat, lon string) ([]*entity.Address, error) {
+	addresses, _, err := p.GeoCodeWithSource(lat, lon)
+	return addresses, err
+}
+
+// GeoCodeWithSource — GeoCode с источником результата: "cache" для ответа
+// из кэша, имя провайдера — для свежего.
+func (p *GeoServiceProxy) GeoCodeWithSource(lat, lon string) ([]*entity.Address, string, error) {
+	start := time.Now()
+	cacheResult := "hit"
+	source := GeoSourceCache
+
+	key := p.cacheKey("geocode", fmt.Sprintf("%s:%s", lat, lon))
+	v, err := p.cache.GetOrSet(key, p.entryTTL(), func() (interface{}, error) {
+		cacheResult = "miss"
+		addresses, s, err := GeoCodeSourced(p.geo, lat, lon)
+		source = s
+		return addresses, err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if addresses, ok := decodeAddresses(v); ok {
+		metrics.GeoRequestDuration.WithLabelValues("geocode", cacheResult).Observe(time.Since(start).Seconds())
+		return addresses, source, nil
+	}
+	addresses, source, err := GeoCodeSourced(p.geo, lat, lon)
+	metrics.GeoRequestDuration.WithLabelValues("geocode", "miss").Observe(time.Since(start).Seconds())
+	return addresses, source, err
+}
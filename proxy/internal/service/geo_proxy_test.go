@@ -0,0 +1,216 @@
+package service
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/cache"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/entity"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/metrics"
+)
+
+// recordingCache запоминает TTL сохранённых записей.
+type recordingCache struct {
+	ttls []time.Duration
+}
+
+func (c *recordingCache) Get(string) (interface{}, bool) { return nil, false }
+
+func (c *recordingCache) Set(_ string, _ interface{}, ttl time.Duration) {
+	c.ttls = append(c.ttls, ttl)
+}
+
+func (c *recordingCache) Delete(string) {}
+
+func (c *recordingCache) Touch(string, time.Duration) bool { return false }
+
+func (c *recordingCache) GetOrSet(_ string, ttl time.Duration, fn func() (interface{}, error)) (interface{}, error) {
+	v, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	c.ttls = append(c.ttls, ttl)
+	return v, nil
+}
+
+func (c *recordingCache) Clear() {}
+func (c *recordingCache) Close() {}
+
+// staticGeo отдаёт один и тот же результат на любой запрос.
+type staticGeo struct{}
+
+func (staticGeo) AddressSearch(string) ([]*entity.Address, error) {
+	return []*entity.Address{{City: "Москва"}}, nil
+}
+
+func (staticGeo) GeoCode(string, string) ([]*entity.Address, error) {
+	return []*entity.Address{{City: "Москва"}}, nil
+}
+
+// sourcedGeo — staticGeo, сообщающий источник результата.
+type sourcedGeo struct{ staticGeo }
+
+func (g sourcedGeo) AddressSearchWithSource(input string) ([]*entity.Address, string, error) {
+	addresses, err := g.AddressSearch(input)
+	return addresses, GeoSourceDadata, err
+}
+
+func (g sourcedGeo) GeoCodeWithSource(lat, lon string) ([]*entity.Address, string, error) {
+	addresses, err := g.GeoCode(lat, lon)
+	return addresses, GeoSourceDadata, err
+}
+
+func TestGeoServiceProxy_SourceTagging(t *testing.T) {
+	c := cache.NewInMemoryCache(time.Hour)
+	t.Cleanup(c.Close)
+	p := NewGeoServiceProxy(sourcedGeo{}, c, time.Hour, 0, 0)
+
+	if _, source, err := p.AddressSearchWithSource("Москва"); err != nil || source != GeoSourceDadata {
+		t.Errorf("fresh result: got source %q (err %v), want %q", source, err, GeoSourceDadata)
+	}
+	if _, source, err := p.AddressSearchWithSource("Москва"); err != nil || source != GeoSourceCache {
+		t.Errorf("cached result: got source %q (err %v), want %q", source, err, GeoSourceCache)
+	}
+
+	if _, source, err := p.GeoCodeWithSource("55.75", "37.61"); err != nil || source != GeoSourceDadata {
+		t.Errorf("fresh geocode: got source %q (err %v), want %q", source, err, GeoSourceDadata)
+	}
+	if _, source, err := p.GeoCodeWithSource("55.75", "37.61"); err != nil || source != GeoSourceCache {
+		t.Errorf("cached geocode: got source %q (err %v), want %q", source, err, GeoSourceCache)
+	}
+}
+
+func TestGeoServiceProxy_TTLJitterWithinBand(t *testing.T) {
+	const (
+		ttl       = 100 * time.Second
+		jitterPct = 10
+	)
+	rec := &recordingCache{}
+	p := NewGeoServiceProxy(staticGeo{}, rec, ttl, jitterPct, 0)
+	p.rnd = rand.New(rand.NewSource(1))
+
+	for i := 0; i < 20; i++ {
+		if _, err := p.AddressSearch(fmt.Sprintf("query %d", i)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	lo := ttl - ttl*jitterPct/100
+	hi := ttl + ttl*jitterPct/100
+	varied := false
+	for i, got := range rec.ttls {
+		if got < lo || got > hi {
+			t.Errorf("ttl[%d] = %s outside band [%s, %s]", i, got, lo, hi)
+		}
+		if got != rec.ttls[0] {
+			varied = true
+		}
+	}
+	if !varied {
+		t.Error("all TTLs identical: jitter not applied")
+	}
+}
+
+func TestGeoServiceProxy_NoJitterKeepsTTL(t *testing.T) {
+	rec := &recordingCache{}
+	p := NewGeoServiceProxy(staticGeo{}, rec, time.Minute, 0, 0)
+
+	if _, err := p.AddressSearch("query"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rec.ttls) != 1 || rec.ttls[0] != time.Minute {
+		t.Errorf("got ttls %v, want exactly [1m0s]", rec.ttls)
+	}
+}
+
+func TestCacheKey_ShortKeysStayReadable(t *testing.T) {
+	p := NewGeoServiceProxy(staticGeo{}, &recordingCache{}, time.Minute, 0, 0)
+
+	if got := p.cacheKey("address_search", "Москва, Тверская 1"); got != "address_search:Москва, Тверская 1" {
+		t.Errorf("short key must stay readable, got %q", got)
+	}
+}
+
+func TestCacheKey_LongKeysBoundedAndStable(t *testing.T) {
+	const maxLen = 64
+	p := NewGeoServiceProxy(staticGeo{}, &recordingCache{}, time.Minute, 0, maxLen)
+
+	long := strings.Repeat("очень длинный адрес ", 50)
+	key := p.cacheKey("address_search", long)
+
+	if len(key) > maxLen {
+		t.Errorf("key length %d exceeds limit %d: %q", len(key), maxLen, key)
+	}
+	if !strings.HasPrefix(key, "address_search:sha256:") {
+		t.Errorf("hashed key must keep its prefix, got %q", key)
+	}
+	if again := p.cacheKey("address_search", long); again != key {
+		t.Errorf("key is not stable: %q vs %q", key, again)
+	}
+	if other := p.cacheKey("address_search", long+"x"); other == key {
+		t.Error("different queries must produce different keys")
+	}
+}
+
+func TestGeoServiceProxy_LongQueryRoundTrips(t *testing.T) {
+	c := cache.NewInMemoryCache(time.Minute)
+	t.Cleanup(c.Close)
+	p := NewGeoServiceProxy(staticGeo{}, c, time.Minute, 0, 64)
+
+	long := strings.Repeat("длинный запрос ", 100)
+	first, err := p.AddressSearch(long)
+	if err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	second, err := p.AddressSearch(long)
+	if err != nil {
+		t.Fatalf("second call: %v", err)
+	}
+	if len(first) != 1 || len(second) != 1 || second[0].City != first[0].City {
+		t.Errorf("cached result mismatch: first %+v, second %+v", first, second)
+	}
+}
+
+// histogramSamples возвращает число наблюдений в дочерней гистограмме.
+func histogramSamples(t *testing.T, operation, cacheResult string) uint64 {
+	t.Helper()
+
+	obs, err := metrics.GeoRequestDuration.GetMetricWithLabelValues(operation, cacheResult)
+	if err != nil {
+		t.Fatalf("get histogram child: %v", err)
+	}
+	m := &dto.Metric{}
+	if err := obs.(prometheus.Metric).Write(m); err != nil {
+		t.Fatalf("write metric: %v", err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestGeoServiceProxy_DurationMetricLabelsCacheResult(t *testing.T) {
+	c := cache.NewInMemoryCache(time.Minute)
+	t.Cleanup(c.Close)
+	p := NewGeoServiceProxy(staticGeo{}, c, time.Minute, 0, 0)
+
+	missBefore := histogramSamples(t, "address_search", "miss")
+	hitBefore := histogramSamples(t, "address_search", "hit")
+
+	if _, err := p.AddressSearch("Москва"); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	if _, err := p.AddressSearch("Москва"); err != nil {
+		t.Fatalf("second call: %v", err)
+	}
+
+	if got := histogramSamples(t, "address_search", "miss") - missBefore; got != 1 {
+		t.Errorf("got %d miss samples, want 1", got)
+	}
+	if got := histogramSamples(t, "address_search", "hit") - hitBefore; got != 1 {
+		t.Errorf("got %d hit samples, want 1", got)
+	}
+}
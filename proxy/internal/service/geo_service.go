@@ -0,0 +1,442 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ekomobile/dadata/v2/api/suggest"
+	"github.com/ekomobile/dadata/v2/client"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/entity"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/metrics"
+)
+
+// GeoServicer — поиск и геокодирование адресов.
+type GeoServicer interface {
+	AddressSearch(input string) ([]*entity.Address, error)
+	GeoCode(lat, lon string) ([]*entity.Address, error)
+}
+
+// Значения источника результата геосервиса для поля source ответов.
+const (
+	GeoSourceDadata    = "dadata"
+	GeoSourceNominatim = "nominatim"
+	GeoSourceCache     = "cache"
+)
+
+// GeoSourcer — необязательная возможность геосервиса сообщать, кто дал
+// результат: имя провайдера или "cache" для ответа из кэша.
+type GeoSourcer interface {
+	AddressSearchWithSource(input string) ([]*entity.Address, string, error)
+	GeoCodeWithSource(lat, lon string) ([]*entity.Address, string, error)
+}
+
+// AddressSearchSourced выполняет поиск, возвращая источник результата,
+// если сервис его сообщает; иначе источник остаётся пустым.
+func AddressSearchSourced(geo GeoServicer, input string) ([]*entity.Address, string, error) {
+	if s, ok := geo.(GeoSourcer); ok {
+		return s.AddressSearchWithSource(input)
+	}
+	addresses, err := geo.AddressSearch(input)
+	return addresses, "", err
+}
+
+// GeoCodeSourced — аналог AddressSearchSourced для геокодирования.
+func GeoCodeSourced(geo GeoServicer, lat, lon string) ([]*entity.Address, string, error) {
+	if s, ok := geo.(GeoSourcer); ok {
+		return s.GeoCodeWithSource(lat, lon)
+	}
+	addresses, err := geo.GeoCode(lat, lon)
+	return addresses, "", err
+}
+
+// defaultDadataBaseURL — базовый адрес облачного API Dadata; on-premise
+// установки задают свой через DADATA_BASE_URL.
+const defaultDadataBaseURL = "https://suggestions.dadata.ru/suggestions/api/4_1/rs"
+
+// defaultMaxGeoResponseBytes — предел размера тела ответа геолокации.
+// Сломанный или вредоносный апстрим не должен заставить нас вычитывать
+// безразмерный ответ в память.
+const defaultMaxGeoResponseBytes = 1 << 20
+
+// ErrGeoResponseTooLarge возвращается, когда тело ответа геолокации
+// превышает установленный предел.
+type ErrGeoResponseTooLarge struct {
+	Limit int64
+}
+
+func (e *ErrGeoResponseTooLarge) Error() string {
+	return fmt.Sprintf("dadata geolocate response exceeds %d bytes", e.Limit)
+}
+
+// ErrGeoRateLimited возвращается, когда Dadata отвечает 429. RetryAfter —
+// рекомендованная пауза из заголовка Retry-After (0, если не указана).
+type ErrGeoRateLimited struct {
+	RetryAfter time.Duration
+}
+
+func (e *ErrGeoRateLimited) Error() string {
+	return fmt.Sprintf("dadata rate limited, retry after %s", e.RetryAfter)
+}
+
+// GeoService — реализация GeoServicer поверх API Dadata.
+type GeoService struct {
+	api          *suggest.Api
+	apiKey       string
+	secretKey    string
+	geolocateURL string
+	userAgent    string
+	httpClient   *http.Client
+
+	// maxResponseBytes — предел размера тела ответа геолокации.
+	maxResponseBytes int64
+
+	// debugLog включает подробное журналирование запросов и ответов
+	// геокодирования; nil — выключено.
+	debugLog *slog.Logger
+
+	// maxResults ограничивает число адресов в ответе AddressSearch;
+	// 0 — без ограничения.
+	maxResults int
+}
+
+// NewGeoService создаёт сервис с переданными ключами Dadata и User-Agent
+// исходящих запросов. Пустой baseURL означает облачный API Dadata.
+func NewGeoService(apiKey, secretKey, userAgent, baseURL string) *GeoService {
+	base := strings.TrimSuffix(baseURL, "/")
+	if base == "" {
+		base = defaultDadataBaseURL
+	}
+
+	endpointURL, _ := url.Parse(base + "/")
+	creds := client.Credentials{
+		ApiKeyValue:    apiKey,
+		SecretKeyValue: secretKey,
+	}
+	api := suggest.Api{
+		Client: client.NewClient(endpointURL, client.WithCredentialProvider(&creds)),
+	}
+	return &GeoService{
+		api:          &api,
+		apiKey:       apiKey,
+		secretKey:    secretKey,
+		geolocateURL: base + "/geolocate/address",
+		userAgent:    userAgent,
+		// Один клиент на весь сервис: соединения и TLS-сессии
+		// переиспользуются между запросами геокодирования. Транспорт
+		// обёрнут учётом исходящих запросов в метриках внешних API.
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: metrics.InstrumentRoundTripper(&http.Transport{
+				MaxIdleConns:        10,
+				MaxIdleConnsPerHost: 10,
+				IdleConnTimeout:     90 * time.Second,
+			}),
+		},
+		maxResponseBytes: defaultMaxGeoResponseBytes,
+	}
+}
+
+// WithDebugLog включает журналирование точных запросов к Dadata и сырых
+// ответов — для разбора конкретных сбоев геокодирования. Ключ API в
+// заголовке Authorization маскируется. Включается флагом GEO_DEBUG и не
+// предназначен для прода: в журналы попадают координаты и адреса.
+func (g *GeoService) WithDebugLog(logger *slog.Logger) *GeoService {
+	g.debugLog = logger
+	return g
+}
+
+// WithMaxResults ограничивает число адресов, возвращаемых AddressSearch
+// клиенту, — независимо от того, сколько подсказок прислала Dadata.
+// n <= 0 означает «без ограничения».
+func (g *GeoService) WithMaxResults(n int) *GeoService {
+	g.maxResults = n
+	return g
+}
+
+// AddressSearch ищет адреса по строке запроса.
+func (g *GeoService) AddressSearch(input string) ([]*entity.Address, error) {
+	if g.debugLog != nil {
+		g.debugLog.Info("dadata suggest request", "query", input)
+	}
+	start := time.Now()
+	suggestions, err := g.api.Address(context.Background(), &suggest.RequestParams{Query: input, Count: 10})
+	metrics.ObserveExternalAPIRequest("dadata_suggest", time.Since(start), apiOutcome(err))
+	if err != nil {
+		return nil, fmt.Errorf("dadata suggest: %w", err)
+	}
+
+	addresses := make([]*entity.Address, 0, len(suggestions))
+	for _, s := range suggestions {
+		if s.Data == nil || s.Data.City == "" || s.Data.Street == "" {
+			continue
+		}
+		addresses = append(addresses, &entity.Address{
+			Value:     s.Value,
+			City:      s.Data.City,
+			Street:    s.Data.Street,
+			House:     s.Data.House,
+			Lat:       s.Data.GeoLat,
+			Lon:       s.Data.GeoLon,
+			Precision: precisionFromQCGeo(s.Data.QualityCodeGeoRaw),
+		})
+	}
+	addresses = dedupeAddresses(addresses)
+	if g.maxResults > 0 && len(addresses) > g.maxResults {
+		addresses = addresses[:g.maxResults]
+	}
+	return addresses, nil
+}
+
+// AddressSearchWithSource — AddressSearch с именем провайдера в источнике.
+func (g *GeoService) AddressSearchWithSource(input string) ([]*entity.Address, string, error) {
+	addresses, err := g.AddressSearch(input)
+	return addresses, GeoSourceDadata, err
+}
+
+// GeoCodeWithSource — GeoCode с именем провайдера в источнике.
+func (g *GeoService) GeoCodeWithSource(lat, lon string) ([]*entity.Address, string, error) {
+	addresses, err := g.GeoCode(lat, lon)
+	return addresses, GeoSourceDadata, err
+}
+
+// dedupeAddresses убирает дубликаты по (city, street, house, lat, lon),
+// сохраняя исходный порядок: Dadata порой присылает подсказки, различающиеся
+// только полями, которые мы отбрасываем.
+func dedupeAddresses(addrs []*entity.Address) []*entity.Address {
+	seen := make(map[string]struct{}, len(addrs))
+	out := make([]*entity.Address, 0, len(addrs))
+	for _, a := range addrs {
+		key := strings.Join([]string{a.City, a.Street, a.House, a.Lat, a.Lon}, "\x00")
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, a)
+	}
+	return out
+}
+
+// apiOutcome классифицирует результат запроса к внешнему API для метрик.
+func apiOutcome(err error) string {
+	if err == nil {
+		return metrics.OutcomeSuccess
+	}
+	var rateLimited *ErrGeoRateLimited
+	if errors.As(err, &rateLimited) {
+		return metrics.OutcomeRateLimited
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return metrics.OutcomeTimeout
+	}
+	return metrics.OutcomeError
+}
+
+// parseRetryAfter разбирает заголовок Retry-After: число секунд или HTTP-дата.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// precisionFromQCGeo переводит код qc_geo Dadata в человекочитаемую точность.
+// Dadata отдаёт код то числом, то строкой, поэтому принимаем оба варианта.
+func precisionFromQCGeo(raw interface{}) string {
+	var code int
+	switch v := raw.(type) {
+	case float64:
+		code = int(v)
+	case int:
+		code = v
+	case string:
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return "unknown"
+		}
+		code = n
+	default:
+		return ""
+	}
+
+	switch code {
+	case 0:
+		return "exact"
+	case 1:
+		return "near_house"
+	case 2:
+		return "street"
+	case 3:
+		return "settlement"
+	case 4:
+		return "city"
+	default:
+		return "unknown"
+	}
+}
+
+// geolocateResponse — тело ответа геолокации Dadata. Экземпляры живут в
+// geoRespPool, чтобы при высокой нагрузке не выделять буфер разбора на
+// каждый запрос.
+type geolocateResponse struct {
+	Suggestions []*suggest.AddressSuggestion `json:"suggestions"`
+}
+
+// reset готовит буфер к повторному использованию: указатели зануляются,
+// чтобы декодер не дописывал поля в подсказки предыдущего ответа, а длина
+// сбрасывается с сохранением ёмкости.
+func (r *geolocateResponse) reset() {
+	for i := range r.Suggestions {
+		r.Suggestions[i] = nil
+	}
+	r.Suggestions = r.Suggestions[:0]
+}
+
+var geoRespPool = sync.Pool{
+	New: func() interface{} { return &geolocateResponse{} },
+}
+
+// geolocatePayload собирает тело запроса геолокации. Координаты разбираются
+// в float64 и сериализуются через encoding/json: подстановка строки напрямую
+// позволила бы значению вида «55,7558» сломать JSON или внедрить лишние поля.
+// NaN, бесконечности и значения вне географического диапазона отклоняются:
+// ParseFloat их принимает, но координатами они не являются (а NaN/Inf ещё и
+// несериализуемы в JSON).
+func geolocatePayload(lat, lon string) ([]byte, error) {
+	latF, err := parseCoordinate(lat, 90)
+	if err != nil {
+		return nil, fmt.Errorf("invalid latitude %q: %w", lat, err)
+	}
+	lonF, err := parseCoordinate(lon, 180)
+	if err != nil {
+		return nil, fmt.Errorf("invalid longitude %q: %w", lon, err)
+	}
+	return json.Marshal(struct {
+		Lat float64 `json:"lat"`
+		Lon float64 `json:"lon"`
+	}{Lat: latF, Lon: lonF})
+}
+
+// parseCoordinate разбирает координату и проверяет попадание в диапазон
+// [-limit, limit] градусов.
+func parseCoordinate(s string, limit float64) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, errors.New("expected a decimal number with a dot")
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, errors.New("not a finite number")
+	}
+	if v < -limit || v > limit {
+		return 0, fmt.Errorf("out of range [-%g, %g]", limit, limit)
+	}
+	return v, nil
+}
+
+// GeoCode возвращает адреса по координатам через геолокацию Dadata.
+func (g *GeoService) GeoCode(lat, lon string) ([]*entity.Address, error) {
+	payload, err := geolocatePayload(lat, lon)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, g.geolocateURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Token "+g.apiKey)
+	if g.userAgent != "" {
+		req.Header.Set("User-Agent", g.userAgent)
+	}
+	if g.debugLog != nil {
+		g.debugLog.Info("dadata geolocate request",
+			"url", g.geolocateURL,
+			"body", string(payload),
+			"authorization", "Token [redacted]",
+		)
+	}
+
+	// Время и исход запроса учитывает обёртка транспорта клиента —
+	// с хостом Dadata в метке api.
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("dadata geolocate: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, &ErrGeoRateLimited{RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("dadata geolocate: unexpected status %d", resp.StatusCode)
+	}
+
+	geoResp := geoRespPool.Get().(*geolocateResponse)
+	geoResp.reset()
+	defer geoRespPool.Put(geoResp)
+
+	// Читаем на байт больше предела: если декодер выбрал всю квоту,
+	// значит тело было больше разрешённого.
+	limited := &io.LimitedReader{R: resp.Body, N: g.maxResponseBytes + 1}
+	var body io.Reader = limited
+	if g.debugLog != nil {
+		raw, err := io.ReadAll(limited)
+		if err != nil {
+			return nil, fmt.Errorf("read geolocate response: %w", err)
+		}
+		g.debugLog.Info("dadata geolocate response",
+			"status", resp.StatusCode,
+			"body", string(raw),
+		)
+		body = bytes.NewReader(raw)
+	}
+	if err := json.NewDecoder(body).Decode(geoResp); err != nil {
+		if limited.N <= 0 {
+			return nil, &ErrGeoResponseTooLarge{Limit: g.maxResponseBytes}
+		}
+		return nil, fmt.Errorf("decode geolocate response: %w", err)
+	}
+	if limited.N <= 0 {
+		return nil, &ErrGeoResponseTooLarge{Limit: g.maxResponseBytes}
+	}
+
+	addresses := make([]*entity.Address, 0, len(geoResp.Suggestions))
+	for _, s := range geoResp.Suggestions {
+		if s.Data == nil {
+			continue
+		}
+		addresses = append(addresses, &entity.Address{
+			Value:     s.Value,
+			City:      s.Data.City,
+			Street:    s.Data.Street,
+			House:     s.Data.House,
+			Lat:       s.Data.GeoLat,
+			Lon:       s.Data.GeoLon,
+			Precision: precisionFromQCGeo(s.Data.QualityCodeGeoRaw),
+		})
+	}
+	return addresses, nil
+}
@@ -0,0 +1,455 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGeoCode_RateLimitedWithRetryAfter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(srv.Close)
+
+	g := NewGeoService("key", "secret", "geoservice/test", "")
+	g.geolocateURL = srv.URL
+
+	_, err := g.GeoCode("55.75", "37.61")
+	if err == nil {
+		t.Fatal("expected error on 429")
+	}
+
+	var rateLimited *ErrGeoRateLimited
+	if !errors.As(err, &rateLimited) {
+		t.Fatalf("got %v, want ErrGeoRateLimited", err)
+	}
+	if rateLimited.RetryAfter != 7*time.Second {
+		t.Errorf("got RetryAfter %s, want 7s", rateLimited.RetryAfter)
+	}
+}
+
+func TestGeoCode_SendsConfiguredUserAgent(t *testing.T) {
+	var gotUA string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUA = r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"suggestions":[]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	g := NewGeoService("key", "secret", "geoservice/test", "")
+	g.geolocateURL = srv.URL
+
+	if _, err := g.GeoCode("55.75", "37.61"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotUA != "geoservice/test" {
+		t.Errorf("got User-Agent %q, want geoservice/test", gotUA)
+	}
+}
+
+func TestGeoCode_ReusesConnections(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"suggestions":[]}`))
+	}))
+
+	var mu sync.Mutex
+	newConns := 0
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			newConns++
+			mu.Unlock()
+		}
+	}
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	g := NewGeoService("key", "secret", "geoservice/test", "")
+	g.geolocateURL = srv.URL
+
+	for i := 0; i < 3; i++ {
+		if _, err := g.GeoCode("55.75", "37.61"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if newConns != 1 {
+		t.Errorf("got %d TCP connections for 3 calls, want 1 (keep-alive reuse)", newConns)
+	}
+}
+
+func TestGeolocatePayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon string
+		want     string
+		wantErr  bool
+	}{
+		{name: "valid", lat: "55.7558", lon: "37.6173", want: `{"lat":55.7558,"lon":37.6173}`},
+		{name: "comma decimal latitude", lat: "55,7558", lon: "37.6173", wantErr: true},
+		{name: "comma decimal longitude", lat: "55.7558", lon: "37,6173", wantErr: true},
+		{name: "garbage", lat: "abc", lon: "37.6173", wantErr: true},
+		{name: "scientific notation", lat: "5.57558e1", lon: "37.6173", want: `{"lat":55.7558,"lon":37.6173}`},
+		{name: "NaN", lat: "NaN", lon: "37.6173", wantErr: true},
+		{name: "infinity", lat: "55.7558", lon: "Inf", wantErr: true},
+		{name: "overflow to infinity", lat: "1e309", lon: "37.6173", wantErr: true},
+		{name: "latitude out of range", lat: "90.0001", lon: "37.6173", wantErr: true},
+		{name: "longitude out of range", lat: "55.7558", lon: "-180.5", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := geolocatePayload(tt.lat, tt.lon)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got body %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got body %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeoCode_InvalidCoordinatesRejectedBeforeRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request must not reach upstream for invalid coordinates")
+	}))
+	t.Cleanup(srv.Close)
+
+	g := NewGeoService("key", "secret", "geoservice/test", "")
+	g.geolocateURL = srv.URL
+
+	if _, err := g.GeoCode("55,7558", "37.6173"); err == nil {
+		t.Fatal("expected error for comma-decimal latitude")
+	}
+}
+
+func TestGeoCode_OversizedResponseRejected(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"suggestions":[{"value":"`))
+		w.Write([]byte(strings.Repeat("x", 256)))
+		w.Write([]byte(`"}]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	g := NewGeoService("key", "secret", "geoservice/test", "")
+	g.geolocateURL = srv.URL
+	g.maxResponseBytes = 64
+
+	_, err := g.GeoCode("55.75", "37.61")
+	var tooLarge *ErrGeoResponseTooLarge
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("got %v, want ErrGeoResponseTooLarge", err)
+	}
+	if tooLarge.Limit != 64 {
+		t.Errorf("got limit %d, want 64", tooLarge.Limit)
+	}
+}
+
+func TestGeoCode_BodyWithinLimitDecodes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"suggestions":[]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	g := NewGeoService("key", "secret", "geoservice/test", "")
+	g.geolocateURL = srv.URL
+
+	if _, err := g.GeoCode("55.75", "37.61"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGeolocateResponse_ResetClearsStaleData(t *testing.T) {
+	gr := &geolocateResponse{}
+	if err := json.Unmarshal([]byte(`{"suggestions":[{"value":"old one"},{"value":"old two"}]}`), gr); err != nil {
+		t.Fatalf("first decode: %v", err)
+	}
+
+	gr.reset()
+	if len(gr.Suggestions) != 0 {
+		t.Fatalf("got %d suggestions after reset, want 0", len(gr.Suggestions))
+	}
+
+	if err := json.Unmarshal([]byte(`{"suggestions":[{"value":"new"}]}`), gr); err != nil {
+		t.Fatalf("second decode: %v", err)
+	}
+	if len(gr.Suggestions) != 1 || gr.Suggestions[0].Value != "new" {
+		t.Errorf("stale data leaked into reused buffer: %+v", gr.Suggestions)
+	}
+}
+
+func BenchmarkGeoCode(b *testing.B) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"suggestions":[
+			{"value":"г Москва, ул Тверская, д 1","data":{"city":"Москва","street":"Тверская","house":"1","geo_lat":"55.75","geo_lon":"37.61"}},
+			{"value":"г Москва, ул Тверская, д 2","data":{"city":"Москва","street":"Тверская","house":"2","geo_lat":"55.76","geo_lon":"37.62"}}
+		]}`))
+	}))
+	b.Cleanup(srv.Close)
+
+	g := NewGeoService("key", "secret", "geoservice/test", "")
+	g.geolocateURL = srv.URL
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := g.GeoCode("55.75", "37.61"); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestNewGeoService_CustomBaseURL(t *testing.T) {
+	var gotPaths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"suggestions":[]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	g := NewGeoService("key", "secret", "geoservice/test", srv.URL)
+
+	if _, err := g.GeoCode("55.75", "37.61"); err != nil {
+		t.Fatalf("GeoCode: %v", err)
+	}
+	if _, err := g.AddressSearch("Москва"); err != nil {
+		t.Fatalf("AddressSearch: %v", err)
+	}
+
+	if len(gotPaths) != 2 {
+		t.Fatalf("got %d requests, want 2: %v", len(gotPaths), gotPaths)
+	}
+	if gotPaths[0] != "/geolocate/address" {
+		t.Errorf("geolocate hit %q, want /geolocate/address", gotPaths[0])
+	}
+	if gotPaths[1] != "/suggest/address" {
+		t.Errorf("suggest hit %q, want /suggest/address", gotPaths[1])
+	}
+}
+
+func TestAddressSearch_DeduplicatesSuggestions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"suggestions":[
+			{"value":"Москва, Тверская 1","data":{"city":"Москва","street":"Тверская","house":"1","geo_lat":"55.757","geo_lon":"37.613"}},
+			{"value":"г Москва, ул Тверская, д 1","data":{"city":"Москва","street":"Тверская","house":"1","geo_lat":"55.757","geo_lon":"37.613"}},
+			{"value":"Москва, Тверская 2","data":{"city":"Москва","street":"Тверская","house":"2","geo_lat":"55.758","geo_lon":"37.614"}}
+		]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	g := NewGeoService("key", "secret", "geoservice/test", srv.URL)
+	addresses, err := g.AddressSearch("Москва Тверская")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(addresses) != 2 {
+		t.Fatalf("got %d addresses, want 2 after dedupe", len(addresses))
+	}
+	if addresses[0].House != "1" || addresses[1].House != "2" {
+		t.Errorf("order must be preserved: %+v", addresses)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{"garbage", 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.in); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPrecisionFromQCGeo(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  interface{}
+		want string
+	}{
+		{"number exact", float64(0), "exact"},
+		{"number near house", float64(1), "near_house"},
+		{"number street", float64(2), "street"},
+		{"number settlement", float64(3), "settlement"},
+		{"number city", float64(4), "city"},
+		{"number undefined", float64(5), "unknown"},
+		{"string street", "2", "street"},
+		{"string garbage", "abc", "unknown"},
+		{"missing code", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := precisionFromQCGeo(tt.raw); got != tt.want {
+				t.Errorf("precisionFromQCGeo(%v) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzParseCoordinates(f *testing.F) {
+	for _, seed := range [][2]string{
+		{"55.7558", "37.6173"},
+		{"NaN", "NaN"},
+		{"Inf", "-Inf"},
+		{"1e309", "-1e309"},
+		{"55,7558", "37,6173"},
+		{"", ""},
+		{"0x1p-2", "0x1p-2"},
+		{"+90", "-180"},
+		{"9999999999999999999999999999", "0.0000000000000000000000001"},
+	} {
+		f.Add(seed[0], seed[1])
+	}
+
+	f.Fuzz(func(t *testing.T, lat, lon string) {
+		payload, err := geolocatePayload(lat, lon)
+		if err != nil {
+			return
+		}
+		// Принятые координаты обязаны давать валидный JSON с конечными
+		// значениями в географическом диапазоне.
+		var out struct {
+			Lat float64 `json:"lat"`
+			Lon float64 `json:"lon"`
+		}
+		if err := json.Unmarshal(payload, &out); err != nil {
+			t.Fatalf("accepted input %q/%q produced invalid JSON %s: %v", lat, lon, payload, err)
+		}
+		if math.IsNaN(out.Lat) || math.IsInf(out.Lat, 0) || out.Lat < -90 || out.Lat > 90 {
+			t.Errorf("latitude %q accepted as %v", lat, out.Lat)
+		}
+		if math.IsNaN(out.Lon) || math.IsInf(out.Lon, 0) || out.Lon < -180 || out.Lon > 180 {
+			t.Errorf("longitude %q accepted as %v", lon, out.Lon)
+		}
+	})
+}
+
+func TestGeoCode_DebugLogging(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"suggestions":[]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Run("enabled logs request and response without the key", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&buf, nil))
+		g := NewGeoService("super-secret-key", "secret", "geoservice/test", srv.URL).WithDebugLog(logger)
+
+		if _, err := g.GeoCode("55.75", "37.61"); err != nil {
+			t.Fatalf("GeoCode: %v", err)
+		}
+
+		logs := buf.String()
+		if !strings.Contains(logs, "dadata geolocate request") {
+			t.Error("request debug record missing")
+		}
+		if !strings.Contains(logs, "dadata geolocate response") {
+			t.Error("response debug record missing")
+		}
+		if !strings.Contains(logs, "55.75") || !strings.Contains(logs, "37.61") {
+			t.Error("request body missing from debug record")
+		}
+		if strings.Contains(logs, "super-secret-key") {
+			t.Error("API key leaked into debug logs")
+		}
+		if !strings.Contains(logs, "[redacted]") {
+			t.Error("authorization header must appear redacted")
+		}
+	})
+
+	t.Run("disabled stays silent", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&buf, nil))
+		_ = logger
+		g := NewGeoService("super-secret-key", "secret", "geoservice/test", srv.URL)
+
+		if _, err := g.GeoCode("55.75", "37.61"); err != nil {
+			t.Fatalf("GeoCode: %v", err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("no debug output expected, got %q", buf.String())
+		}
+	})
+}
+
+func TestAddressSearch_MaxResults(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"suggestions":[
+			{"value":"Москва, Тверская 1","data":{"city":"Москва","street":"Тверская","house":"1"}},
+			{"value":"Москва, Тверская 2","data":{"city":"Москва","street":"Тверская","house":"2"}},
+			{"value":"Москва, Тверская 3","data":{"city":"Москва","street":"Тверская","house":"3"}}
+		]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Run("truncates at the cap", func(t *testing.T) {
+		g := NewGeoService("key", "secret", "geoservice/test", srv.URL).WithMaxResults(2)
+		addresses, err := g.AddressSearch("Москва Тверская")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(addresses) != 2 {
+			t.Fatalf("got %d addresses, want cap of 2", len(addresses))
+		}
+		if addresses[0].House != "1" || addresses[1].House != "2" {
+			t.Errorf("first results must be kept in order: %+v", addresses)
+		}
+	})
+
+	t.Run("passes through under the cap", func(t *testing.T) {
+		g := NewGeoService("key", "secret", "geoservice/test", srv.URL).WithMaxResults(10)
+		addresses, err := g.AddressSearch("Москва Тверская")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(addresses) != 3 {
+			t.Errorf("got %d addresses, want all 3", len(addresses))
+		}
+	})
+
+	t.Run("zero means unlimited", func(t *testing.T) {
+		g := NewGeoService("key", "secret", "geoservice/test", srv.URL)
+		addresses, err := g.AddressSearch("Москва Тверская")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(addresses) != 3 {
+			t.Errorf("got %d addresses, want all 3", len(addresses))
+		}
+	})
+}
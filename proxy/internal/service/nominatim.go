@@ -0,0 +1,164 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/entity"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/metrics"
+)
+
+// defaultNominatimBaseURL — публичный инстанс Nominatim (OSM).
+const defaultNominatimBaseURL = "https://nominatim.openstreetmap.org"
+
+// NominatimGeoService — реализация GeoServicer поверх Nominatim. Используется
+// как альтернативный провайдер геокодирования (GEO_PROVIDER=nominatim).
+type NominatimGeoService struct {
+	baseURL   string
+	userAgent string
+	client    *http.Client
+
+	// maxResults ограничивает число адресов в ответе AddressSearch;
+	// 0 — без ограничения.
+	maxResults int
+}
+
+// NewNominatimGeoService создаёт сервис поверх Nominatim по указанному
+// базовому адресу. Пустой baseURL означает публичный инстанс OSM.
+// userAgent подставляется в исходящие запросы — политика Nominatim требует
+// осмысленный User-Agent.
+func NewNominatimGeoService(baseURL, userAgent string) *NominatimGeoService {
+	base := strings.TrimSuffix(baseURL, "/")
+	if base == "" {
+		base = defaultNominatimBaseURL
+	}
+	return &NominatimGeoService{
+		baseURL:   base,
+		userAgent: userAgent,
+		// Таймаут ограничивает зависший апстрим: интерфейс GeoServicer не
+		// несёт контекста, поэтому без него обработчик ждал бы бесконечно.
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// WithMaxResults ограничивает число адресов, возвращаемых AddressSearch, —
+// та же настройка GEO_MAX_RESULTS, что и у провайдера Dadata.
+// n <= 0 означает «без ограничения».
+func (g *NominatimGeoService) WithMaxResults(n int) *NominatimGeoService {
+	g.maxResults = n
+	return g
+}
+
+// nominatimPlace — релевантная часть ответа Nominatim.
+type nominatimPlace struct {
+	DisplayName string `json:"display_name"`
+	Lat         string `json:"lat"`
+	Lon         string `json:"lon"`
+	Address     struct {
+		City        string `json:"city"`
+		Town        string `json:"town"`
+		Village     string `json:"village"`
+		Road        string `json:"road"`
+		HouseNumber string `json:"house_number"`
+	} `json:"address"`
+}
+
+// toAddress переводит ответ Nominatim в наш формат адреса. Городом считается
+// первый непустой из city/town/village.
+func (p *nominatimPlace) toAddress() *entity.Address {
+	city := p.Address.City
+	if city == "" {
+		city = p.Address.Town
+	}
+	if city == "" {
+		city = p.Address.Village
+	}
+	return &entity.Address{
+		Value:  p.DisplayName,
+		City:   city,
+		Street: p.Address.Road,
+		House:  p.Address.HouseNumber,
+		Lat:    p.Lat,
+		Lon:    p.Lon,
+	}
+}
+
+func (g *NominatimGeoService) get(api, rawURL string, dst interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("nominatim: %w", err)
+	}
+	if g.userAgent != "" {
+		req.Header.Set("User-Agent", g.userAgent)
+	}
+
+	start := time.Now()
+	resp, err := g.client.Do(req)
+	elapsed := time.Since(start)
+	if err != nil {
+		metrics.ObserveExternalAPIRequest(api, elapsed, apiOutcome(err))
+		return fmt.Errorf("nominatim: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		metrics.ObserveExternalAPIRequest(api, elapsed, metrics.OutcomeError)
+		return fmt.Errorf("nominatim: unexpected status %d", resp.StatusCode)
+	}
+	metrics.ObserveExternalAPIRequest(api, elapsed, metrics.OutcomeSuccess)
+
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("decode nominatim response: %w", err)
+	}
+	return nil
+}
+
+// AddressSearch ищет адреса по строке запроса.
+func (g *NominatimGeoService) AddressSearch(input string) ([]*entity.Address, error) {
+	rawURL := g.baseURL + "/search?format=jsonv2&addressdetails=1&q=" + url.QueryEscape(input)
+
+	var places []*nominatimPlace
+	if err := g.get("nominatim_search", rawURL, &places); err != nil {
+		return nil, err
+	}
+
+	addresses := make([]*entity.Address, 0, len(places))
+	for _, p := range places {
+		addresses = append(addresses, p.toAddress())
+	}
+	if g.maxResults > 0 && len(addresses) > g.maxResults {
+		addresses = addresses[:g.maxResults]
+	}
+	return addresses, nil
+}
+
+// GeoCode возвращает адрес по координатам через обратное геокодирование.
+func (g *NominatimGeoService) GeoCode(lat, lon string) ([]*entity.Address, error) {
+	rawURL := fmt.Sprintf("%s/reverse?format=jsonv2&addressdetails=1&lat=%s&lon=%s",
+		g.baseURL, url.QueryEscape(lat), url.QueryEscape(lon))
+
+	var place nominatimPlace
+	if err := g.get("nominatim_reverse", rawURL, &place); err != nil {
+		return nil, err
+	}
+	if place.DisplayName == "" {
+		return []*entity.Address{}, nil
+	}
+	return []*entity.Address{place.toAddress()}, nil
+}
+
+// AddressSearchWithSource — AddressSearch с именем провайдера в источнике.
+func (g *NominatimGeoService) AddressSearchWithSource(input string) ([]*entity.Address, string, error) {
+	addresses, err := g.AddressSearch(input)
+	return addresses, GeoSourceNominatim, err
+}
+
+// GeoCodeWithSource — GeoCode с именем провайдера в источнике.
+func (g *NominatimGeoService) GeoCodeWithSource(lat, lon string) ([]*entity.Address, string, error) {
+	addresses, err := g.GeoCode(lat, lon)
+	return addresses, GeoSourceNominatim, err
+}
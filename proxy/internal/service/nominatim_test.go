@@ -0,0 +1,110 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNominatim_AddressSearchMapping(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search" {
+			t.Errorf("got path %q, want /search", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("q"); got != "Москва Тверская 1" {
+			t.Errorf("got q %q, want original query", got)
+		}
+		if got := r.Header.Get("User-Agent"); got != "geoservis-test" {
+			t.Errorf("got User-Agent %q, want geoservis-test", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{
+				"display_name": "1, Тверская улица, Москва",
+				"lat": "55.757",
+				"lon": "37.613",
+				"address": {"city": "Москва", "road": "Тверская улица", "house_number": "1"}
+			},
+			{
+				"display_name": "Дом в деревне",
+				"lat": "56.1",
+				"lon": "38.2",
+				"address": {"village": "Грибки", "road": "Лесная"}
+			}
+		]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	g := NewNominatimGeoService(srv.URL, "geoservis-test")
+	addresses, err := g.AddressSearch("Москва Тверская 1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(addresses) != 2 {
+		t.Fatalf("got %d addresses, want 2", len(addresses))
+	}
+
+	first := addresses[0]
+	if first.City != "Москва" || first.Street != "Тверская улица" || first.House != "1" {
+		t.Errorf("unexpected mapping: %+v", first)
+	}
+	if first.Lat != "55.757" || first.Lon != "37.613" {
+		t.Errorf("coordinates not mapped: %+v", first)
+	}
+	if addresses[1].City != "Грибки" {
+		t.Errorf("village must map to City, got %q", addresses[1].City)
+	}
+}
+
+func TestNominatim_MaxResults(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"display_name": "Один", "address": {"city": "Москва"}},
+			{"display_name": "Два", "address": {"city": "Москва"}},
+			{"display_name": "Три", "address": {"city": "Москва"}}
+		]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	g := NewNominatimGeoService(srv.URL, "geoservis-test").WithMaxResults(2)
+	addresses, err := g.AddressSearch("Москва")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(addresses) != 2 {
+		t.Errorf("got %d addresses, want 2 (capped)", len(addresses))
+	}
+}
+
+func TestNominatim_GeoCodeMapping(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/reverse" {
+			t.Errorf("got path %q, want /reverse", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("lat") != "55.757" || q.Get("lon") != "37.613" {
+			t.Errorf("coordinates not passed: %v", q)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"display_name": "1, Тверская улица, Москва",
+			"lat": "55.757",
+			"lon": "37.613",
+			"address": {"town": "Москва", "road": "Тверская улица", "house_number": "1"}
+		}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	g := NewNominatimGeoService(srv.URL, "geoservis-test")
+	addresses, err := g.GeoCode("55.757", "37.613")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(addresses) != 1 {
+		t.Fatalf("got %d addresses, want 1", len(addresses))
+	}
+	if addresses[0].City != "Москва" || addresses[0].House != "1" {
+		t.Errorf("unexpected mapping: %+v", addresses[0])
+	}
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/cache"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/entity"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/mailer"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/repository"
+)
+
+// Доменные ошибки сервиса. ErrInvalidCredentials — общий сигнал «вход
+// отклонён»; конкретные причины оборачивают его, поэтому вызывающему коду
+// достаточно errors.Is(err, ErrInvalidCredentials), а метрики могут
+// различать частные случаи.
+var (
+	// ErrInvalidCredentials возвращается при любом отклонённом входе.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrUserNotFound возвращается при попытке входа с неизвестным email.
+	ErrUserNotFound = fmt.Errorf("%w: user not found", ErrInvalidCredentials)
+	// ErrInvalidPassword возвращается при входе с неверным паролем.
+	ErrInvalidPassword = fmt.Errorf("%w: invalid password", ErrInvalidCredentials)
+)
+
+// UserService — бизнес-логика работы с пользователями.
+type UserService struct {
+	repo   repository.UserRepository
+	mail   mailer.Mailer
+	logger *slog.Logger
+
+	// readCache — необязательный кэш чтения пользователей с коротким TTL:
+	// горячие пользователи (например, владелец токена) не ходят в базу на
+	// каждый запрос. nil — кэширование выключено.
+	readCache cache.Cache
+	readTTL   time.Duration
+}
+
+// NewUserService создаёт сервис поверх репозитория пользователей.
+func NewUserService(repo repository.UserRepository, mail mailer.Mailer, logger *slog.Logger) *UserService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if mail == nil {
+		mail = mailer.NewLogMailer(logger)
+	}
+	return &UserService{repo: repo, mail: mail, logger: logger}
+}
+
+// WithReadCache включает кэширование чтения пользователей с заданным TTL.
+// Записи по id и email инвалидируются при изменении и удалении; запись под
+// прежним email после смены адреса доживает не дольше TTL, поэтому TTL
+// должен быть коротким.
+func (s *UserService) WithReadCache(c cache.Cache, ttl time.Duration) *UserService {
+	s.readCache = c
+	s.readTTL = ttl
+	return s
+}
+
+// cachedUser приводит закэшированное значение к пользователю: кэш в памяти
+// хранит структуру, кэш в базе — сырой JSON.
+func cachedUser(v interface{}) (*entity.User, bool) {
+	switch val := v.(type) {
+	case *entity.User:
+		return val, true
+	case json.RawMessage:
+		var user entity.User
+		if err := json.Unmarshal(val, &user); err != nil {
+			return nil, false
+		}
+		return &user, true
+	default:
+		return nil, false
+	}
+}
+
+// cacheUser сохраняет пользователя под ключами id и email.
+func (s *UserService) cacheUser(user *entity.User) {
+	if s.readCache == nil {
+		return
+	}
+	s.readCache.Set(fmt.Sprintf("user:id:%d", user.ID), user, s.readTTL)
+	s.readCache.Set("user:email:"+user.Email, user, s.readTTL)
+}
+
+// invalidateUser убирает пользователя из кэша чтения.
+func (s *UserService) invalidateUser(id int, email string) {
+	if s.readCache == nil {
+		return
+	}
+	s.readCache.Delete(fmt.Sprintf("user:id:%d", id))
+	if email != "" {
+		s.readCache.Delete("user:email:" + email)
+	}
+}
+
+// RegisterUser создаёт пользователя с захэшированным паролем.
+func (s *UserService) RegisterUser(ctx context.Context, email, password string) (*entity.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("hash password: %w", err)
+	}
+
+	user := &entity.User{
+		Email:        email,
+		PasswordHash: string(hash),
+	}
+	if err := s.repo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Login проверяет учётные данные и возвращает пользователя.
+func (s *UserService) Login(ctx context.Context, email, password string) (*entity.User, error) {
+	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("get user by email: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidPassword
+	}
+	return user, nil
+}
+
+// GetUser возвращает пользователя по идентификатору.
+func (s *UserService) GetUser(ctx context.Context, id int) (*entity.User, error) {
+	if s.readCache != nil {
+		if v, ok := s.readCache.Get(fmt.Sprintf("user:id:%d", id)); ok {
+			if user, ok := cachedUser(v); ok {
+				return user, nil
+			}
+		}
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.cacheUser(user)
+	return user, nil
+}
+
+// GetUserByEmail возвращает пользователя по email.
+func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	if s.readCache != nil {
+		if v, ok := s.readCache.Get("user:email:" + email); ok {
+			if user, ok := cachedUser(v); ok {
+				return user, nil
+			}
+		}
+	}
+
+	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	s.cacheUser(user)
+	return user, nil
+}
+
+// UpdateUser сохраняет изменения пользователя.
+func (s *UserService) UpdateUser(ctx context.Context, user *entity.User) error {
+	if err := s.repo.Update(ctx, user); err != nil {
+		return err
+	}
+	s.invalidateUser(user.ID, user.Email)
+	return nil
+}
+
+// PatchUser частично обновляет пользователя: применяет только непустые
+// поля, остальные остаются прежними. Пароль передаётся открытым текстом
+// и хэшируется здесь.
+func (s *UserService) PatchUser(ctx context.Context, id int, email, password string) (*entity.User, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if email != "" {
+		user.Email = email
+	}
+	if password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("hash password: %w", err)
+		}
+		user.PasswordHash = string(hash)
+	}
+
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	s.invalidateUser(user.ID, user.Email)
+	return user, nil
+}
+
+// SetUserLocation сохраняет город и улицу пользователя.
+func (s *UserService) SetUserLocation(ctx context.Context, id int, city, street string) error {
+	if err := s.repo.SetLocation(ctx, id, city, street); err != nil {
+		return err
+	}
+	s.invalidateUser(id, "")
+	return nil
+}
+
+// DeleteUser помечает пользователя удалённым с необязательной причиной.
+func (s *UserService) DeleteUser(ctx context.Context, id int, reason string) error {
+	if err := s.repo.Delete(ctx, id, reason); err != nil {
+		return err
+	}
+	// Email удалённого пользователя здесь неизвестен; запись по email
+	// доживёт не дольше короткого TTL.
+	s.invalidateUser(id, "")
+	return nil
+}
+
+// DeleteUsersBatch помечает пользователей удалёнными одной транзакцией и
+// возвращает id фактически удалённых.
+func (s *UserService) DeleteUsersBatch(ctx context.Context, ids []int) ([]int, error) {
+	deleted, err := s.repo.DeleteBatch(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range deleted {
+		s.invalidateUser(id, "")
+	}
+	return deleted, nil
+}
+
+// ListUsers возвращает страницу пользователей.
+func (s *UserService) ListUsers(ctx context.Context, limit, offset int) ([]*entity.User, error) {
+	return s.repo.List(ctx, limit, offset)
+}
+
+// ListAllUsers возвращает страницу пользователей, включая удалённых.
+func (s *UserService) ListAllUsers(ctx context.Context, limit, offset int) ([]*entity.User, error) {
+	return s.repo.ListAll(ctx, limit, offset)
+}
+
+// ListUsersCreatedBetween возвращает страницу пользователей, созданных
+// в интервале [from, to).
+func (s *UserService) ListUsersCreatedBetween(ctx context.Context, from, to time.Time, limit, offset int) ([]*entity.User, error) {
+	return s.repo.ListCreatedBetween(ctx, from, to, limit, offset)
+}
+
+// RequestEmailChange сохраняет новый email как отложенный и отправляет на
+// него токен подтверждения через mailer.
+func (s *UserService) RequestEmailChange(ctx context.Context, currentEmail, newEmail string) error {
+	user, err := s.repo.GetByEmail(ctx, currentEmail)
+	if err != nil {
+		return err
+	}
+	if _, err := s.repo.GetByEmail(ctx, newEmail); err == nil {
+		return repository.ErrAlreadyExists
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+
+	token, err := newVerificationToken()
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SetPendingEmail(ctx, user.ID, newEmail, token); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Ваш токен подтверждения смены email: %s", token)
+	if err := s.mail.Send(ctx, newEmail, "Подтверждение смены email", body); err != nil {
+		s.logger.Error("send verification mail", "user_id", user.ID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// ConfirmEmailChange применяет отложенную смену email по токену подтверждения.
+func (s *UserService) ConfirmEmailChange(ctx context.Context, currentEmail, token string) error {
+	user, err := s.repo.GetByEmail(ctx, currentEmail)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.CommitPendingEmail(ctx, user.ID, token); err != nil {
+		return err
+	}
+	// Как и остальные мутации: без инвалидации записи user:id и старого
+	// user:email отдавали бы прежний адрес до истечения TTL.
+	s.invalidateUser(user.ID, currentEmail)
+	return nil
+}
+
+// newVerificationToken генерирует случайный hex-токен подтверждения.
+func newVerificationToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate verification token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// ListUsersAfter возвращает страницу пользователей с id больше afterID.
+func (s *UserService) ListUsersAfter(ctx context.Context, afterID, limit int) ([]*entity.User, error) {
+	return s.repo.ListAfter(ctx, afterID, limit)
+}
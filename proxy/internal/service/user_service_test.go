@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/cache"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/entity"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/repository"
+)
+
+// stubRepo — минимальный репозиторий для тестов смены email. Неиспользуемые
+// методы наследуются от встроенного интерфейса и паникуют при вызове.
+type stubRepo struct {
+	repository.UserRepository
+	user         *entity.User
+	pendingEmail string
+	pendingToken string
+}
+
+func (s *stubRepo) GetByEmail(_ context.Context, email string) (*entity.User, error) {
+	if s.user != nil && s.user.Email == email {
+		return s.user, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (s *stubRepo) GetByID(_ context.Context, id int) (*entity.User, error) {
+	if s.user != nil && s.user.ID == id {
+		return s.user, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (s *stubRepo) Update(_ context.Context, u *entity.User) error {
+	if s.user == nil || s.user.ID != u.ID {
+		return repository.ErrNotFound
+	}
+	s.user = u
+	return nil
+}
+
+func (s *stubRepo) SetPendingEmail(_ context.Context, id int, email, token string) error {
+	if s.user == nil || s.user.ID != id {
+		return repository.ErrNotFound
+	}
+	s.pendingEmail = email
+	s.pendingToken = token
+	return nil
+}
+
+func (s *stubRepo) CommitPendingEmail(_ context.Context, id int, token string) error {
+	if s.user == nil || s.user.ID != id || s.pendingToken == "" || s.pendingToken != token {
+		return repository.ErrNotFound
+	}
+	s.user.Email = s.pendingEmail
+	s.pendingEmail = ""
+	s.pendingToken = ""
+	return nil
+}
+
+type sentMail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// recordingMailer запоминает отправленные письма вместо реальной отправки.
+type recordingMailer struct {
+	sent []sentMail
+}
+
+func (m *recordingMailer) Send(_ context.Context, to, subject, body string) error {
+	m.sent = append(m.sent, sentMail{To: to, Subject: subject, Body: body})
+	return nil
+}
+
+func TestRequestEmailChange_SendsVerificationMail(t *testing.T) {
+	repo := &stubRepo{user: &entity.User{ID: 1, Email: "old@example.com"}}
+	mail := &recordingMailer{}
+	svc := NewUserService(repo, mail, nil)
+
+	if err := svc.RequestEmailChange(context.Background(), "old@example.com", "new@example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(mail.sent) != 1 {
+		t.Fatalf("got %d mails, want 1", len(mail.sent))
+	}
+	if mail.sent[0].To != "new@example.com" {
+		t.Errorf("mail sent to %q, want new@example.com", mail.sent[0].To)
+	}
+	if repo.pendingToken == "" {
+		t.Fatal("no pending token stored")
+	}
+	if !strings.Contains(mail.sent[0].Body, repo.pendingToken) {
+		t.Errorf("mail body %q does not contain token %q", mail.sent[0].Body, repo.pendingToken)
+	}
+}
+
+func TestConfirmEmailChange_CommitsAfterMailedToken(t *testing.T) {
+	repo := &stubRepo{user: &entity.User{ID: 1, Email: "old@example.com"}}
+	mail := &recordingMailer{}
+	svc := NewUserService(repo, mail, nil)
+
+	if err := svc.RequestEmailChange(context.Background(), "old@example.com", "new@example.com"); err != nil {
+		t.Fatalf("request change: %v", err)
+	}
+	if err := svc.ConfirmEmailChange(context.Background(), "old@example.com", repo.pendingToken); err != nil {
+		t.Fatalf("confirm change: %v", err)
+	}
+	if repo.user.Email != "new@example.com" {
+		t.Errorf("email not committed: %q", repo.user.Email)
+	}
+}
+
+func TestPatchUser_EmailOnlyKeepsPasswordHash(t *testing.T) {
+	repo := &stubRepo{user: &entity.User{
+		ID:           1,
+		Email:        "old@example.com",
+		PasswordHash: "original-hash",
+	}}
+	svc := NewUserService(repo, nil, nil)
+
+	user, err := svc.PatchUser(context.Background(), 1, "new@example.com", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if user.Email != "new@example.com" {
+		t.Errorf("got email %q, want new@example.com", user.Email)
+	}
+	if user.PasswordHash != "original-hash" {
+		t.Errorf("password hash must be untouched, got %q", user.PasswordHash)
+	}
+	if repo.user.Email != "new@example.com" {
+		t.Error("patched user must be saved")
+	}
+}
+
+func TestPatchUser_PasswordOnlyRehashes(t *testing.T) {
+	repo := &stubRepo{user: &entity.User{
+		ID:           1,
+		Email:        "user@example.com",
+		PasswordHash: "original-hash",
+	}}
+	svc := NewUserService(repo, nil, nil)
+
+	user, err := svc.PatchUser(context.Background(), 1, "", "new-password")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if user.Email != "user@example.com" {
+		t.Errorf("email must be untouched, got %q", user.Email)
+	}
+	if user.PasswordHash == "original-hash" {
+		t.Error("password hash must be replaced")
+	}
+}
+
+func TestPatchUser_UnknownID(t *testing.T) {
+	svc := NewUserService(&stubRepo{}, nil, nil)
+
+	if _, err := svc.PatchUser(context.Background(), 42, "new@example.com", ""); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("got %v, want ErrNotFound", err)
+	}
+}
+
+// countingRepo считает обращения к GetByID поверх stubRepo.
+type countingRepo struct {
+	*stubRepo
+	getByIDCalls int
+}
+
+func (c *countingRepo) GetByID(ctx context.Context, id int) (*entity.User, error) {
+	c.getByIDCalls++
+	return c.stubRepo.GetByID(ctx, id)
+}
+
+func TestGetUser_SecondReadServedFromCache(t *testing.T) {
+	repo := &countingRepo{stubRepo: &stubRepo{user: &entity.User{ID: 1, Email: "user@example.com"}}}
+	userCache := cache.NewInMemoryCache(time.Minute)
+	t.Cleanup(userCache.Close)
+	svc := NewUserService(repo, nil, nil).WithReadCache(userCache, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if _, err := svc.GetUser(context.Background(), 1); err != nil {
+			t.Fatalf("get user: %v", err)
+		}
+	}
+
+	if repo.getByIDCalls != 1 {
+		t.Errorf("got %d repository reads, want 1 (rest from cache)", repo.getByIDCalls)
+	}
+}
+
+func TestUpdateUser_InvalidatesReadCache(t *testing.T) {
+	repo := &countingRepo{stubRepo: &stubRepo{user: &entity.User{ID: 1, Email: "user@example.com"}}}
+	userCache := cache.NewInMemoryCache(time.Minute)
+	t.Cleanup(userCache.Close)
+	svc := NewUserService(repo, nil, nil).WithReadCache(userCache, time.Minute)
+
+	if _, err := svc.GetUser(context.Background(), 1); err != nil {
+		t.Fatalf("warm cache: %v", err)
+	}
+
+	updated := &entity.User{ID: 1, Email: "new@example.com", PasswordHash: "hash"}
+	if err := svc.UpdateUser(context.Background(), updated); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	user, err := svc.GetUser(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("get after update: %v", err)
+	}
+	if user.Email != "new@example.com" {
+		t.Errorf("got stale email %q after update", user.Email)
+	}
+	if repo.getByIDCalls != 2 {
+		t.Errorf("got %d repository reads, want 2 (cache invalidated by update)", repo.getByIDCalls)
+	}
+}
+
+func TestConfirmEmailChange_InvalidatesReadCache(t *testing.T) {
+	repo := &countingRepo{stubRepo: &stubRepo{user: &entity.User{ID: 1, Email: "old@example.com"}}}
+	userCache := cache.NewInMemoryCache(time.Minute)
+	t.Cleanup(userCache.Close)
+	mail := &recordingMailer{}
+	svc := NewUserService(repo, mail, nil).WithReadCache(userCache, time.Minute)
+
+	if _, err := svc.GetUser(context.Background(), 1); err != nil {
+		t.Fatalf("warm cache: %v", err)
+	}
+	if err := svc.RequestEmailChange(context.Background(), "old@example.com", "new@example.com"); err != nil {
+		t.Fatalf("request change: %v", err)
+	}
+	if err := svc.ConfirmEmailChange(context.Background(), "old@example.com", repo.pendingToken); err != nil {
+		t.Fatalf("confirm change: %v", err)
+	}
+
+	user, err := svc.GetUser(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("get after commit: %v", err)
+	}
+	if user.Email != "new@example.com" {
+		t.Errorf("got stale email %q after committed change", user.Email)
+	}
+	if _, err := svc.GetUserByEmail(context.Background(), "old@example.com"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("old email must not be served from cache, got err %v", err)
+	}
+}
+
+func TestGetUser_NoCacheConfiguredHitsRepo(t *testing.T) {
+	repo := &countingRepo{stubRepo: &stubRepo{user: &entity.User{ID: 1, Email: "user@example.com"}}}
+	svc := NewUserService(repo, nil, nil)
+
+	for i := 0; i < 2; i++ {
+		if _, err := svc.GetUser(context.Background(), 1); err != nil {
+			t.Fatalf("get user: %v", err)
+		}
+	}
+	if repo.getByIDCalls != 2 {
+		t.Errorf("got %d repository reads, want 2 without cache", repo.getByIDCalls)
+	}
+}
+
+func TestLogin_UnknownEmailIsInvalidCredentials(t *testing.T) {
+	svc := NewUserService(&stubRepo{}, nil, nil)
+
+	_, err := svc.Login(context.Background(), "nobody@example.com", "secret")
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("got %v, want ErrUserNotFound", err)
+	}
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("got %v, want wrapped ErrInvalidCredentials", err)
+	}
+}
+
+func TestLogin_WrongPasswordIsInvalidCredentials(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("right-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	repo := &stubRepo{user: &entity.User{ID: 1, Email: "user@example.com", PasswordHash: string(hash)}}
+	svc := NewUserService(repo, nil, nil)
+
+	_, err = svc.Login(context.Background(), "user@example.com", "wrong-password")
+	if !errors.Is(err, ErrInvalidPassword) {
+		t.Errorf("got %v, want ErrInvalidPassword", err)
+	}
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("got %v, want wrapped ErrInvalidCredentials", err)
+	}
+}
@@ -0,0 +1,35 @@
+// Package version хранит сведения о сборке, заполняемые через ldflags:
+//
+//	go build -ldflags "-X .../internal/version.Version=1.2.3 \
+//	  -X .../internal/version.Commit=$(git rev-parse --short HEAD) \
+//	  -X .../internal/version.BuiltAt=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import "runtime"
+
+var (
+	// Version — версия релиза.
+	Version = "dev"
+	// Commit — хэш коммита, из которого собран бинарник.
+	Commit = "unknown"
+	// BuiltAt — время сборки в формате RFC3339.
+	BuiltAt = "unknown"
+)
+
+// Info — сведения о сборке для ответа API.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuiltAt   string `json:"built_at"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get возвращает сведения о текущей сборке.
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuiltAt:   BuiltAt,
+		GoVersion: runtime.Version(),
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/cache"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/config"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/controller"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/db"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/hugo"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/mailer"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/middleware"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/migrations"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/repository"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/responder"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/server"
+	"github.com/sizotov81-hub/Geoservis/proxy/internal/service"
+)
+
+// @title           Geoservis API
+// @version         1.0
+// @description     Геолокационный сервис: поиск и геокодирование адресов через Dadata.
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+
+func main() {
+	cfg := config.Load()
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	logger.Info("effective configuration", cfg.LogFields()...)
+
+	adapter, err := db.NewSQLAdapter(cfg)
+	if err != nil {
+		logger.Error("database connection failed", "error", err)
+		os.Exit(1)
+	}
+	defer adapter.Close()
+
+	if err := migrations.RunIfEnabled(adapter.DB.DB, cfg.RunMigrations, logger); err != nil {
+		logger.Error("migrations failed", "error", err)
+		os.Exit(1)
+	}
+
+	var geoCache cache.Cache
+	if cfg.CacheBackend == "postgres" {
+		geoCache = cache.NewPostgresCache(adapter.DB, cfg.CacheCleanupInterval, logger)
+	} else {
+		geoCache = cache.NewInMemoryCache(cfg.CacheCleanupInterval).
+			WithCleanupBatch(cfg.CacheCleanupBatch)
+	}
+	defer geoCache.Close()
+	var geoService service.GeoServicer
+	if cfg.GeoProvider == "nominatim" {
+		geoService = service.NewNominatimGeoService(cfg.NominatimBaseURL, cfg.GeoUserAgent).
+			WithMaxResults(cfg.GeoMaxResults)
+	} else {
+		gs := service.NewGeoService(cfg.DadataAPIKey, cfg.DadataSecretKey, cfg.GeoUserAgent, cfg.DadataBaseURL).
+			WithMaxResults(cfg.GeoMaxResults)
+		if cfg.GeoDebug {
+			gs = gs.WithDebugLog(logger)
+		}
+		geoService = gs
+	}
+	geoProxy := service.NewGeoServiceProxy(geoService, geoCache, cfg.CacheTTL, cfg.CacheTTLJitterPct, cfg.CacheMaxKeyLen)
+
+	userRepo := repository.NewUserRepository(adapter.DB, cfg.DBQueryTimeout, cfg.DBSlowQueryThreshold, logger)
+	mail := mailer.NewFromConfig(cfg, logger)
+	userService := service.NewUserService(userRepo, mail, logger)
+	if cfg.UserCacheTTL > 0 {
+		userCache := cache.NewInMemoryCache(cfg.CacheCleanupInterval).
+			WithCleanupBatch(cfg.CacheCleanupBatch)
+		defer userCache.Close()
+		userService = userService.WithReadCache(userCache, cfg.UserCacheTTL)
+	}
+
+	rsp := responder.NewJSONResponder(logger).WithPretty(cfg.PrettyJSON)
+	maintenance := middleware.NewMaintenance(cfg.MaintenanceMode)
+
+	fence, err := controller.ParseGeoFence(cfg.GeoFence)
+	if err != nil {
+		logger.Error("invalid GEO_FENCE", "error", err)
+		os.Exit(1)
+	}
+	geoController := controller.NewGeoController(geoProxy, rsp).WithGeoFence(fence)
+
+	router := controller.NewRouter(controller.RouterDeps{
+		Cfg:         cfg,
+		Logger:      logger,
+		Auth:        controller.NewAuthController(userService, cfg, rsp),
+		Users:       controller.NewUserController(userService, geoProxy, rsp, cfg),
+		Geo:         geoController,
+		Pprof:       controller.NewPprofController(cfg.PprofDir, cfg.PprofRetention, cfg.PprofMaxDuration, rsp),
+		Health:      controller.NewHealthController(adapter, rsp),
+		Admin:       controller.NewAdminController(maintenance, geoCache, userService, cfg, logger, rsp),
+		Maintenance: maintenance,
+	})
+
+	go hugo.WorkerTest(cfg.HugoDir, logger)
+
+	tracker := &server.ConnTracker{}
+	srv := &http.Server{
+		Addr:         cfg.ListenAddr,
+		Handler:      router,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+		ConnState:    tracker.ConnState,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		logger.Info("server starting", "addr", cfg.ListenAddr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("server failed", "error", err)
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	draining := tracker.Open()
+	logger.Info("draining connections", "open", draining, "active", tracker.Active())
+	start := time.Now()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("shutdown timed out",
+			"error", err,
+			"remaining", tracker.Open(),
+			"elapsed", time.Since(start).String(),
+		)
+		return
+	}
+	logger.Info("server stopped",
+		"drained", draining,
+		"elapsed", time.Since(start).String(),
+	)
+}
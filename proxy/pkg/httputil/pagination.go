@@ -0,0 +1,27 @@
+// Package httputil содержит вспомогательные функции для HTTP-обработчиков.
+package httputil
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// ParsePagination читает параметры limit и offset из строки запроса.
+// Отсутствующий, нечисловой или неположительный limit заменяется defaultLimit,
+// limit сверх maxLimit урезается до maxLimit; отрицательный или нечисловой
+// offset становится нулём.
+func ParsePagination(r *http.Request, defaultLimit, maxLimit int) (limit, offset int) {
+	limit, _ = strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if maxLimit > 0 && limit > maxLimit {
+		limit = maxLimit
+	}
+
+	offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
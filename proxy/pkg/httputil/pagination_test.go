@@ -0,0 +1,40 @@
+package httputil
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantLimit  int
+		wantOffset int
+	}{
+		{name: "missing params", query: "", wantLimit: 20, wantOffset: 0},
+		{name: "valid params", query: "?limit=50&offset=10", wantLimit: 50, wantOffset: 10},
+		{name: "negative limit", query: "?limit=-5", wantLimit: 20, wantOffset: 0},
+		{name: "zero limit", query: "?limit=0", wantLimit: 20, wantOffset: 0},
+		{name: "over max limit", query: "?limit=500", wantLimit: 100, wantOffset: 0},
+		{name: "negative offset", query: "?offset=-3", wantLimit: 20, wantOffset: 0},
+		{name: "garbage values", query: "?limit=abc&offset=xyz", wantLimit: 20, wantOffset: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/users"+tt.query, nil)
+			limit, offset := ParsePagination(r, 20, 100)
+			if limit != tt.wantLimit || offset != tt.wantOffset {
+				t.Errorf("got (%d, %d), want (%d, %d)", limit, offset, tt.wantLimit, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestParsePagination_NoMaxLimit(t *testing.T) {
+	r := httptest.NewRequest("GET", "/users?limit=500", nil)
+	limit, _ := ParsePagination(r, 20, 0)
+	if limit != 500 {
+		t.Errorf("got limit %d, want 500 when max is disabled", limit)
+	}
+}